@@ -2,16 +2,42 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"gopkg.in/yaml.v3"
 
 	"github.com/influenzanet/user-management-service/internal/config"
+	"github.com/influenzanet/user-management-service/pkg/dbs/auditdb"
 	"github.com/influenzanet/user-management-service/pkg/dbs/globaldb"
 	"github.com/influenzanet/user-management-service/pkg/dbs/userdb"
+	"github.com/influenzanet/user-management-service/pkg/messageclient"
 	"github.com/influenzanet/user-management-service/pkg/models"
 	"github.com/influenzanet/user-management-service/pkg/service"
 )
 
 func main() {
-	conf := config.InitConfig()
+	printConfig := flag.Bool("print-config", false, "print the resolved configuration (with secrets redacted) and exit")
+	flag.Parse()
+
+	conf, err := config.InitConfig()
+	if *printConfig {
+		out, renderErr := yaml.Marshal(conf.Redacted())
+		if renderErr != nil {
+			log.Fatalf("failed to render configuration: %v", renderErr)
+		}
+		fmt.Fprint(os.Stdout, string(out))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\nconfiguration is invalid: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err != nil {
+		log.Fatalf("failed to load configuration: %v", err)
+	}
 
 	clients := &models.APIClients{}
 	// Connect to authentication service
@@ -21,8 +47,15 @@ func main() {
 	/*
 	 */
 
+	messagingClient, err := messageclient.Connect(conf.MessagingService)
+	if err != nil {
+		log.Fatalf("failed to connect to messaging service: %v", err)
+	}
+	clients.MessagingService = messagingClient
+
 	userDBService := userdb.NewUserDBService(conf.UserDBConfig)
 	globalDBService := globaldb.NewGlobalDBService(conf.GlobalDBConfig)
+	auditDBService := auditdb.NewAuditDBService(conf.AuditDBConfig)
 
 	ctx := context.Background()
 
@@ -33,5 +66,10 @@ func main() {
 		userDBService,
 		globalDBService,
 		conf.JWT,
+		conf.Verification,
+		conf.Reauth,
+		conf.Challenge,
+		auditDBService,
+		conf.RateLimit,
 	)
 }
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/influenzanet/user-management-service/pkg/models"
+)
+
+// InitConfig assembles the service configuration: it starts from the YAML
+// file at USER_MGMT_CONFIG (if set), overlays any environment variables that
+// are present, and validates the result. It returns an error instead of
+// exiting so that main can decide how to report it (and so --print-config
+// can inspect a config that fails validation).
+func InitConfig() (Config, error) {
+	conf := Config{}
+
+	if path := os.Getenv("USER_MGMT_CONFIG"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("reading config file %q: %w", path, err)
+		}
+		if err := yaml.Unmarshal(data, &conf); err != nil {
+			return Config{}, fmt.Errorf("parsing config file %q: %w", path, err)
+		}
+	}
+
+	if err := applyEnvOverrides(&conf); err != nil {
+		return Config{}, err
+	}
+
+	if err := conf.Validate(); err != nil {
+		// Unlike the errors above, conf is still a fully-parsed, well-formed
+		// value here - just one that fails a business-rule check - so it is
+		// returned alongside the error instead of discarded, letting
+		// --print-config show an operator what's wrong.
+		return conf, err
+	}
+	return conf, nil
+}
+
+// applyEnvOverrides overlays environment variables on top of conf, for
+// every setting that can be configured that way. A variable that is unset is
+// left untouched; one that is set but malformed (e.g. a non-integer
+// duration) is reported as an error.
+func applyEnvOverrides(conf *Config) error {
+	var problems []string
+	errf := func(name string, err error) {
+		problems = append(problems, fmt.Sprintf("%s: %s", name, err.Error()))
+	}
+
+	if v, ok := os.LookupEnv("USER_MANAGEMENT_LISTEN_PORT"); ok {
+		conf.Port = v
+	}
+	if v, ok := os.LookupEnv("JWT_ISSUER"); ok {
+		conf.JWT.Issuer = v
+	}
+	if v, ok := os.LookupEnv("ALLOWED_INSTANCES"); ok {
+		conf.AllowedInstances = splitAndTrim(v)
+	}
+
+	if v, ok := os.LookupEnv("ADDR_MESSAGING_SERVICE"); ok {
+		conf.MessagingService.Addr = v
+	}
+	if v, ok := os.LookupEnv("MESSAGING_SERVICE_USE_TLS"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			errf("MESSAGING_SERVICE_USE_TLS", err)
+		} else {
+			conf.MessagingService.UseTLS = b
+		}
+	}
+	if v, ok := os.LookupEnv("MESSAGING_SERVICE_CA_CERT_FILE"); ok {
+		conf.MessagingService.CACertFile = v
+	}
+	if conf.MessagingService.Timeout == 0 {
+		conf.MessagingService.Timeout = 10 * time.Second
+	}
+
+	if err := applyDBEnvOverrides("USER_DB", &conf.UserDBConfig); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if err := applyDBEnvOverrides("GLOBAL_DB", &conf.GlobalDBConfig); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if err := applyDBEnvOverrides("AUDIT_DB", &conf.AuditDBConfig); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if v, ok := os.LookupEnv("JWT_SIGNING_KEY"); ok {
+		conf.JWT.SigningKey = v
+	}
+	if v, ok := os.LookupEnv("JWT_SIGNING_ALGORITHM"); ok {
+		conf.JWT.SigningAlgorithm = v
+	}
+	if v, ok := os.LookupEnv("TOKEN_EXPIRATION_MIN"); ok {
+		if err := setMinutes(&conf.JWT.TokenExpiryInterval, v); err != nil {
+			errf("TOKEN_EXPIRATION_MIN", err)
+		}
+	}
+	if v, ok := os.LookupEnv("TOKEN_MINIMUM_AGE_MIN"); ok {
+		if err := setMinutes(&conf.JWT.TokenMinimumAgeMin, v); err != nil {
+			errf("TOKEN_MINIMUM_AGE_MIN", err)
+		}
+	}
+
+	if v, ok := os.LookupEnv("VERIFICATION_TOKEN_SIGNING_KEY"); ok {
+		conf.Verification.SigningKey = v
+	}
+	if v, ok := os.LookupEnv("VERIFICATION_TOKEN_EXPIRATION_MIN"); ok {
+		if err := setMinutes(&conf.Verification.TokenExpiry, v); err != nil {
+			errf("VERIFICATION_TOKEN_EXPIRATION_MIN", err)
+		}
+	}
+	if v, ok := os.LookupEnv("VERIFICATION_EMAIL_RESEND_INTERVAL_MIN"); ok {
+		if err := setMinutes(&conf.Verification.ResendMinInterval, v); err != nil {
+			errf("VERIFICATION_EMAIL_RESEND_INTERVAL_MIN", err)
+		}
+	}
+
+	if v, ok := os.LookupEnv("REAUTH_TOKEN_SIGNING_KEY"); ok {
+		conf.Reauth.SigningKey = v
+	}
+	if v, ok := os.LookupEnv("REAUTH_TOKEN_EXPIRATION_MIN"); ok {
+		if err := setMinutes(&conf.Reauth.TokenExpiry, v); err != nil {
+			errf("REAUTH_TOKEN_EXPIRATION_MIN", err)
+		}
+	}
+
+	if v, ok := os.LookupEnv("LOGIN_CHALLENGE_TOKEN_SIGNING_KEY"); ok {
+		conf.Challenge.SigningKey = v
+	}
+	if v, ok := os.LookupEnv("LOGIN_CHALLENGE_TOKEN_EXPIRATION_MIN"); ok {
+		if err := setMinutes(&conf.Challenge.TokenExpiry, v); err != nil {
+			errf("LOGIN_CHALLENGE_TOKEN_EXPIRATION_MIN", err)
+		}
+	}
+
+	if v, ok := os.LookupEnv("AUDIT_LOG_RETENTION_DAYS"); ok {
+		days, err := strconv.Atoi(v)
+		if err != nil {
+			errf("AUDIT_LOG_RETENTION_DAYS", err)
+		} else {
+			conf.AuditLogRetention = time.Hour * 24 * time.Duration(days)
+		}
+	}
+
+	if v, ok := os.LookupEnv("RATE_LIMIT_MAX_FAILED_ATTEMPTS"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			errf("RATE_LIMIT_MAX_FAILED_ATTEMPTS", err)
+		} else {
+			conf.RateLimit.MaxFailedAttempts = n
+		}
+	}
+	if v, ok := os.LookupEnv("RATE_LIMIT_LOCKOUT_DURATION_MIN"); ok {
+		if err := setMinutes(&conf.RateLimit.LockoutDuration, v); err != nil {
+			errf("RATE_LIMIT_LOCKOUT_DURATION_MIN", err)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid environment overrides:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// applyDBEnvOverrides overlays the USER_DB/GLOBAL_DB/AUDIT_DB-style env vars
+// onto db, building a mongodb connection URI from the connection
+// string/username/password the same way the previous hand-rolled loader did.
+func applyDBEnvOverrides(prefix string, db *models.DBConfig) error {
+	connStr, hasConnStr := os.LookupEnv(prefix + "_CONNECTION_STR")
+	username, hasUsername := os.LookupEnv(prefix + "_USERNAME")
+	password, hasPassword := os.LookupEnv(prefix + "_PASSWORD")
+	if hasConnStr || hasUsername || hasPassword {
+		connPrefix := os.Getenv(prefix + "_CONNECTION_PREFIX") // used in test mode
+		db.URI = fmt.Sprintf("mongodb%s://%s:%s@%s", connPrefix, username, password, connStr)
+	}
+
+	if v, ok := os.LookupEnv("DB_TIMEOUT"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("DB_TIMEOUT: %w", err)
+		}
+		db.Timeout = n
+	}
+	if v, ok := os.LookupEnv("DB_IDLE_CONN_TIMEOUT"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("DB_IDLE_CONN_TIMEOUT: %w", err)
+		}
+		db.IdleConnTimeout = n
+	}
+	if v, ok := os.LookupEnv("DB_MAX_POOL_SIZE"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("DB_MAX_POOL_SIZE: %w", err)
+		}
+		db.MaxPoolSize = uint64(n)
+	}
+	if v, ok := os.LookupEnv("DB_DB_NAME_PREFIX"); ok {
+		db.DBNamePrefix = v
+	}
+	return nil
+}
+
+func setMinutes(d *time.Duration, v string) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return err
+	}
+	*d = time.Minute * time.Duration(n)
+	return nil
+}
+
+func splitAndTrim(v string) []string {
+	parts := strings.Split(v, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+)
+
+// UserManagementApiServer is the server API for the UserManagementApi service.
+type UserManagementApiServer interface {
+	Status(context.Context, *empty.Empty) (*ServiceStatus, error)
+	LoginWithEmail(context.Context, *LoginWithEmailMsg) (*UserAuthInfo, error)
+	SignupWithEmail(context.Context, *SignupWithEmailMsg) (*UserAuthInfo, error)
+	CheckRefreshToken(context.Context, *RefreshTokenRequest) (*ServiceStatus, error)
+	TokenRefreshed(context.Context, *RefreshTokenRequest) (*ServiceStatus, error)
+	SwitchProfile(context.Context, *ProfileRequest) (*UserAuthInfo, error)
+	VerifyAccount(context.Context, *VerifyAccountMsg) (*ServiceStatus, error)
+	ResendVerificationEmail(context.Context, *ResendVerificationEmailMsg) (*ServiceStatus, error)
+	Reauthenticate(context.Context, *ReauthRequest) (*SensitiveActionToken, error)
+	ChangePassword(context.Context, *ChangePasswordMsg) (*ServiceStatus, error)
+	ChangeEmail(context.Context, *ChangeEmailMsg) (*ServiceStatus, error)
+	DeleteAccount(context.Context, *DeleteAccountMsg) (*ServiceStatus, error)
+	ChangeUserRoles(context.Context, *ChangeUserRolesMsg) (*ServiceStatus, error)
+	EnableTOTP(context.Context, *EnableTOTPMsg) (*EnableTOTPResponse, error)
+	ConfirmTOTP(context.Context, *ConfirmTOTPMsg) (*ServiceStatus, error)
+	DisableTOTP(context.Context, *DisableTOTPMsg) (*ServiceStatus, error)
+	VerifyTOTP(context.Context, *VerifyTOTPMsg) (*ServiceStatus, error)
+	LoginWithSecondFactor(context.Context, *LoginWithSecondFactorMsg) (*UserAuthInfo, error)
+	ListSessions(context.Context, *ListSessionsMsg) (*ListSessionsResponse, error)
+	RevokeSession(context.Context, *RevokeSessionMsg) (*ServiceStatus, error)
+	RevokeAllSessions(context.Context, *RevokeAllSessionsMsg) (*ServiceStatus, error)
+	GetAuditLog(context.Context, *GetAuditLogMsg) (*AuditLogResponse, error)
+	QueryAuditLog(context.Context, *QueryAuditLogMsg) (*AuditLogResponse, error)
+	UnlockAccount(context.Context, *UnlockAccountMsg) (*ServiceStatus, error)
+}
+
+// RegisterUserManagementApiServer registers srv as the implementation backing
+// the UserManagementApi gRPC service.
+func RegisterUserManagementApiServer(s grpc.ServiceRegistrar, srv UserManagementApiServer) {
+	s.RegisterService(&userManagementApiServiceDesc, srv)
+}
+
+var userManagementApiServiceDesc = grpc.ServiceDesc{
+	ServiceName: "api.UserManagementApi",
+	HandlerType: (*UserManagementApiServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams:     []grpc.StreamDesc{},
+	Metadata:    "user-management-service.proto",
+}
@@ -0,0 +1,278 @@
+package api
+
+// ServiceStatus_Status mirrors the status enum used across influenzanet
+// services' Status RPCs.
+type ServiceStatus_Status int32
+
+const (
+	ServiceStatus_NORMAL ServiceStatus_Status = 0
+	ServiceStatus_ERROR  ServiceStatus_Status = 1
+)
+
+// ServiceStatus is returned by RPCs that only need to report a simple
+// success/failure outcome.
+type ServiceStatus struct {
+	Status  ServiceStatus_Status
+	Msg     string
+	Version string
+}
+
+// TokenInfos carries the parsed claims of a caller's access token.
+type TokenInfos struct {
+	Id         string
+	InstanceId string
+	IssuedAt   int64
+	Payload    map[string]string
+	Roles      []string
+}
+
+// Account is the public representation of models.Account.
+type Account struct {
+	AccountId          string
+	AccountConfirmedAt int64
+	PreferredLanguage  string
+}
+
+// Profile is the public representation of models.Profile.
+type Profile struct {
+	Id       string
+	Nickname string
+	AvatarId string
+}
+
+// User is the public representation of models.User.
+type User struct {
+	Account  *Account
+	Profiles []*Profile
+}
+
+// LoginWithEmailMsg is the request message for LoginWithEmail.
+type LoginWithEmailMsg struct {
+	Email      string
+	Password   string
+	InstanceId string
+}
+
+// SignupWithEmailMsg is the request message for SignupWithEmail.
+type SignupWithEmailMsg struct {
+	Email             string
+	Password          string
+	InstanceId        string
+	PreferredLanguage string
+	WantsNewsletter   bool
+}
+
+// UserAuthInfo is returned by RPCs that authenticate a user. When a second
+// factor is required to complete login, SecondFactorRequired is set and only
+// ChallengeToken/InstanceId are populated; the caller must redeem the
+// challenge via LoginWithSecondFactor to receive the full response.
+type UserAuthInfo struct {
+	UserId               string
+	Roles                []string
+	InstanceId           string
+	AccountId            string
+	AccountConfirmed     bool
+	Profiles             []*Profile
+	SelectedProfile      *Profile
+	PreferredLanguage    string
+	SecondFactorRequired bool
+	ChallengeToken       string
+}
+
+// RefreshTokenRequest is used by CheckRefreshToken and TokenRefreshed.
+type RefreshTokenRequest struct {
+	RefreshToken string
+	UserId       string
+	InstanceId   string
+	// DeviceFingerprint identifies the client the token was issued to, used to
+	// group rotations into a single session.
+	DeviceFingerprint string
+	// PreviousToken is the refresh token being rotated away from. Read by
+	// TokenRefreshed so the new token can be linked into the same session's
+	// rotation lineage; ignored by CheckRefreshToken.
+	PreviousToken string
+}
+
+// ProfileRequest is used by SwitchProfile.
+type ProfileRequest struct {
+	Token   *TokenInfos
+	Profile *Profile
+}
+
+// VerifyAccountMsg is the request message for VerifyAccount.
+type VerifyAccountMsg struct {
+	InstanceId string
+	Token      string
+}
+
+// ResendVerificationEmailMsg is the request message for ResendVerificationEmail.
+type ResendVerificationEmailMsg struct {
+	InstanceId string
+	Email      string
+}
+
+// ReauthRequest is the request message for Reauthenticate. Password or Otp
+// must be set. Reauthenticate checks Password when it is set; a request
+// carrying only Otp is rejected with codes.Unimplemented, as OTP-based
+// reauthentication is not supported.
+type ReauthRequest struct {
+	Token    *TokenInfos
+	Scope    string
+	Password string
+	Otp      string
+}
+
+// SensitiveActionToken is returned by Reauthenticate and must be presented,
+// alongside the caller's normal access token, to perform the operation it
+// was scoped for.
+type SensitiveActionToken struct {
+	Token     string
+	ExpiresAt int64
+}
+
+// ChangePasswordMsg is the request message for ChangePassword.
+type ChangePasswordMsg struct {
+	Token       *TokenInfos
+	ReauthToken string
+	OldPassword string
+	NewPassword string
+}
+
+// ChangeEmailMsg is the request message for ChangeEmail.
+type ChangeEmailMsg struct {
+	Token       *TokenInfos
+	ReauthToken string
+	NewEmail    string
+}
+
+// DeleteAccountMsg is the request message for DeleteAccount.
+type DeleteAccountMsg struct {
+	Token       *TokenInfos
+	ReauthToken string
+}
+
+// ChangeUserRolesMsg is the request message for ChangeUserRoles. It is
+// admin-scoped: Token identifies the admin performing the change, UserId the
+// account being modified.
+type ChangeUserRolesMsg struct {
+	Token       *TokenInfos
+	ReauthToken string
+	UserId      string
+	NewRoles    []string
+}
+
+// EnableTOTPMsg is the request message for EnableTOTP. ReauthToken is only
+// required when the account already has a confirmed TOTP secret, to
+// re-provision a new one.
+type EnableTOTPMsg struct {
+	Token       *TokenInfos
+	ReauthToken string
+}
+
+// EnableTOTPResponse carries the freshly provisioned (but not yet confirmed)
+// TOTP secret, its QR-encodable provisioning URI, and one-time recovery
+// codes shown to the user exactly once.
+type EnableTOTPResponse struct {
+	Secret          string
+	ProvisioningUri string
+	RecoveryCodes   []string
+}
+
+// ConfirmTOTPMsg is the request message for ConfirmTOTP.
+type ConfirmTOTPMsg struct {
+	Token *TokenInfos
+	Code  string
+}
+
+// DisableTOTPMsg is the request message for DisableTOTP.
+type DisableTOTPMsg struct {
+	Token       *TokenInfos
+	ReauthToken string
+}
+
+// VerifyTOTPMsg is the request message for VerifyTOTP, used to step up an
+// already-authenticated session (as opposed to completing login).
+type VerifyTOTPMsg struct {
+	Token *TokenInfos
+	Code  string
+}
+
+// LoginWithSecondFactorMsg is the request message for LoginWithSecondFactor.
+type LoginWithSecondFactorMsg struct {
+	ChallengeToken string
+	Code           string
+}
+
+// SessionInfo is the public representation of a models.RefreshSession, as
+// returned by ListSessions.
+type SessionInfo struct {
+	SessionId         string
+	DeviceFingerprint string
+	IssuedAt          int64
+	LastUsedAt        int64
+	Current           bool
+}
+
+// ListSessionsMsg is the request message for ListSessions.
+type ListSessionsMsg struct {
+	Token *TokenInfos
+}
+
+// ListSessionsResponse is returned by ListSessions.
+type ListSessionsResponse struct {
+	Sessions []*SessionInfo
+}
+
+// RevokeSessionMsg is the request message for RevokeSession.
+type RevokeSessionMsg struct {
+	Token     *TokenInfos
+	SessionId string
+}
+
+// RevokeAllSessionsMsg is the request message for RevokeAllSessions.
+type RevokeAllSessionsMsg struct {
+	Token *TokenInfos
+}
+
+// AuditLogEntry is the public representation of models.AuditEntry.
+type AuditLogEntry struct {
+	UserId    string
+	EventType string
+	Timestamp int64
+	SourceIp  string
+	UserAgent string
+}
+
+// GetAuditLogMsg is the request message for GetAuditLog. It is scoped to the
+// caller's own account: entries for other users are never returned.
+type GetAuditLogMsg struct {
+	Token  *TokenInfos
+	Limit  int64
+	Offset int64
+}
+
+// QueryAuditLogMsg is the request message for QueryAuditLog. It is
+// admin-scoped and can filter across any user of the instance.
+type QueryAuditLogMsg struct {
+	Token     *TokenInfos
+	UserId    string
+	EventType string
+	Since     int64
+	Until     int64
+	Limit     int64
+	Offset    int64
+}
+
+// AuditLogResponse is returned by GetAuditLog and QueryAuditLog.
+type AuditLogResponse struct {
+	Entries []*AuditLogEntry
+	Total   int64
+}
+
+// UnlockAccountMsg is the request message for UnlockAccount. It is
+// admin-scoped: Token identifies the admin performing the unlock, UserId the
+// account being unlocked.
+type UnlockAccountMsg struct {
+	Token  *TokenInfos
+	UserId string
+}
@@ -0,0 +1,99 @@
+// Package audit records security-relevant events (logins, signups, password
+// changes, ...) for later review by the account owner or an administrator.
+package audit
+
+import (
+	"log"
+	"time"
+
+	"github.com/influenzanet/user-management-service/pkg/models"
+)
+
+// Event types emitted by pkg/service. Kept as plain strings (rather than an
+// enum) so new event types can be added without a schema migration.
+const (
+	EventLoginSuccess       = "login.success"
+	EventLoginFailure       = "login.failure"
+	EventSignup             = "signup"
+	EventPasswordChanged    = "password.changed"
+	EventEmailChanged       = "email.changed"
+	EventAccountConfirmed   = "account.confirmed"
+	EventAccountDeleted     = "account.deleted"
+	EventProfileSwitched    = "profile.switched"
+	EventTokenRefreshed     = "token.refreshed"
+	EventTokenReuseDetected = "token.reuse_detected"
+	EventSessionRevoked     = "session.revoked"
+	EventTOTPEnrolled       = "totp.enrolled"
+	EventTOTPDisabled       = "totp.disabled"
+	EventRoleChanged        = "role.changed"
+	EventAccountLocked      = "account.locked"
+	EventAccountUnlocked    = "account.unlocked"
+)
+
+// Store persists and retrieves audit entries. Implemented by pkg/dbs/auditdb.
+type Store interface {
+	Write(entry models.AuditEntry) error
+	Query(instanceID string, filter Filter, pagination Pagination) ([]models.AuditEntry, int64, error)
+	DeleteBefore(instanceID string, cutoff int64) (int64, error)
+}
+
+// Filter narrows down a QueryAuditLog/GetAuditLog call.
+type Filter struct {
+	UserID    string
+	EventType string
+	Since     int64
+	Until     int64
+}
+
+// Pagination limits the result set of a query.
+type Pagination struct {
+	Limit  int64
+	Offset int64
+}
+
+// Writer buffers audit entries and persists them on a background goroutine
+// so that emitting an event never blocks the request path it instruments.
+type Writer struct {
+	store Store
+	queue chan models.AuditEntry
+	done  chan struct{}
+}
+
+// NewWriter starts a Writer backed by store with the given buffer size.
+func NewWriter(store Store, bufferSize int) *Writer {
+	w := &Writer{
+		store: store,
+		queue: make(chan models.AuditEntry, bufferSize),
+		done:  make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Emit enqueues entry for asynchronous persistence. If the buffer is full the
+// entry is dropped and logged rather than blocking the caller.
+func (w *Writer) Emit(entry models.AuditEntry) {
+	if entry.Timestamp == 0 {
+		entry.Timestamp = time.Now().Unix()
+	}
+	select {
+	case w.queue <- entry:
+	default:
+		log.Printf("audit: buffer full, dropping event %q for instance %s", entry.EventType, entry.InstanceID)
+	}
+}
+
+// Close stops accepting new entries and waits for the queue to drain.
+func (w *Writer) Close() {
+	close(w.queue)
+	<-w.done
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+	for entry := range w.queue {
+		if err := w.store.Write(entry); err != nil {
+			log.Printf("audit: failed to persist event %q: %s", entry.EventType, err.Error())
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package auditdb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/influenzanet/user-management-service/pkg/audit"
+	"github.com/influenzanet/user-management-service/pkg/models"
+)
+
+// AuditDBService stores audit log entries in a per-instance collection.
+type AuditDBService struct {
+	DBClient     *mongo.Client
+	DBNamePrefix string
+	Timeout      int
+}
+
+// NewAuditDBService connects to the audit DB using the given configuration.
+func NewAuditDBService(conf models.DBConfig) *AuditDBService {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(conf.Timeout)*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(conf.URI).SetMaxPoolSize(conf.MaxPoolSize))
+	if err != nil {
+		panic(err)
+	}
+
+	return &AuditDBService{
+		DBClient:     client,
+		DBNamePrefix: conf.DBNamePrefix,
+		Timeout:      conf.Timeout,
+	}
+}
+
+func (s *AuditDBService) collection(instanceID string) *mongo.Collection {
+	return s.DBClient.Database(s.DBNamePrefix + instanceID).Collection("auditLog")
+}
+
+func (s *AuditDBService) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(s.Timeout)*time.Second)
+}
+
+// Write persists a single audit entry. Implements audit.Store.
+func (s *AuditDBService) Write(entry models.AuditEntry) error {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	_, err := s.collection(entry.InstanceID).InsertOne(ctx, entry)
+	return err
+}
+
+// Query returns the audit entries matching filter, most recent first, along
+// with the total number of matches (ignoring pagination). Implements
+// audit.Store.
+func (s *AuditDBService) Query(instanceID string, filter audit.Filter, pagination audit.Pagination) ([]models.AuditEntry, int64, error) {
+	query := bson.M{}
+	if filter.UserID != "" {
+		query["userID"] = filter.UserID
+	}
+	if filter.EventType != "" {
+		query["eventType"] = filter.EventType
+	}
+	if filter.Since != 0 || filter.Until != 0 {
+		ts := bson.M{}
+		if filter.Since != 0 {
+			ts["$gte"] = filter.Since
+		}
+		if filter.Until != 0 {
+			ts["$lte"] = filter.Until
+		}
+		query["timestamp"] = ts
+	}
+
+	ctx, cancel := s.context()
+	defer cancel()
+
+	total, err := s.collection(instanceID).CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSort(bson.M{"timestamp": -1})
+	if pagination.Limit > 0 {
+		opts.SetLimit(pagination.Limit)
+	}
+	if pagination.Offset > 0 {
+		opts.SetSkip(pagination.Offset)
+	}
+
+	cursor, err := s.collection(instanceID).Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []models.AuditEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// DeleteBefore removes entries older than cutoff, returning how many were
+// removed. Implements audit.Store.
+func (s *AuditDBService) DeleteBefore(instanceID string, cutoff int64) (int64, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	res, err := s.collection(instanceID).DeleteMany(ctx, bson.M{"timestamp": bson.M{"$lt": cutoff}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
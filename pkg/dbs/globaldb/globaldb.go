@@ -0,0 +1,67 @@
+package globaldb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/influenzanet/user-management-service/pkg/models"
+)
+
+// Instance describes a study/platform instance served by this deployment.
+type Instance struct {
+	InstanceID string `bson:"instanceID"`
+}
+
+// GlobalDBService wraps the collections that are shared across all instances.
+type GlobalDBService struct {
+	DBClient     *mongo.Client
+	DBNamePrefix string
+	Timeout      int
+}
+
+// NewGlobalDBService connects to the global DB using the given configuration.
+func NewGlobalDBService(conf models.DBConfig) *GlobalDBService {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(conf.Timeout)*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(conf.URI).SetMaxPoolSize(conf.MaxPoolSize))
+	if err != nil {
+		panic(err)
+	}
+
+	return &GlobalDBService{
+		DBClient:     client,
+		DBNamePrefix: conf.DBNamePrefix,
+		Timeout:      conf.Timeout,
+	}
+}
+
+func (s *GlobalDBService) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(s.Timeout)*time.Second)
+}
+
+func (s *GlobalDBService) instancesCollection() *mongo.Collection {
+	return s.DBClient.Database(s.DBNamePrefix + "global").Collection("instances")
+}
+
+// GetAllInstances returns the list of configured instances.
+func (s *GlobalDBService) GetAllInstances() ([]Instance, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	cursor, err := s.instancesCollection().Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var instances []Instance
+	if err := cursor.All(ctx, &instances); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
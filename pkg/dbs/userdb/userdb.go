@@ -0,0 +1,270 @@
+package userdb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/influenzanet/user-management-service/pkg/models"
+)
+
+// UserDBService wraps the per-instance user collections in the user DB.
+type UserDBService struct {
+	DBClient     *mongo.Client
+	DBNamePrefix string
+	Timeout      int
+}
+
+// NewUserDBService connects to the user DB using the given configuration.
+func NewUserDBService(conf models.DBConfig) *UserDBService {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(conf.Timeout)*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(conf.URI).SetMaxPoolSize(conf.MaxPoolSize))
+	if err != nil {
+		panic(err)
+	}
+
+	return &UserDBService{
+		DBClient:     client,
+		DBNamePrefix: conf.DBNamePrefix,
+		Timeout:      conf.Timeout,
+	}
+}
+
+func (s *UserDBService) collection(instanceID string) *mongo.Collection {
+	return s.DBClient.Database(s.DBNamePrefix + instanceID).Collection("users")
+}
+
+func (s *UserDBService) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(s.Timeout)*time.Second)
+}
+
+// GetUserByEmail fetches a user document by its account email.
+func (s *UserDBService) GetUserByEmail(instanceID string, email string) (models.User, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	var user models.User
+	err := s.collection(instanceID).FindOne(ctx, bson.M{"account.accountID": email}).Decode(&user)
+	return user, err
+}
+
+// GetUserByID fetches a user document by its ID.
+func (s *UserDBService) GetUserByID(instanceID string, id string) (models.User, error) {
+	objID, err := stringToObjectID(id)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	ctx, cancel := s.context()
+	defer cancel()
+
+	var user models.User
+	err = s.collection(instanceID).FindOne(ctx, bson.M{"_id": objID}).Decode(&user)
+	return user, err
+}
+
+// AddUser inserts a new user document and returns its generated ID.
+func (s *UserDBService) AddUser(instanceID string, user models.User) (string, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	res, err := s.collection(instanceID).InsertOne(ctx, user)
+	if err != nil {
+		return "", err
+	}
+	id, ok := res.InsertedID.(interface{ Hex() string })
+	if !ok {
+		return "", errors.New("unexpected inserted ID type")
+	}
+	return id.Hex(), nil
+}
+
+// UpdateUser replaces the full user document.
+func (s *UserDBService) UpdateUser(instanceID string, user models.User) (models.User, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	_, err := s.collection(instanceID).ReplaceOne(ctx, bson.M{"_id": user.ID}, user)
+	return user, err
+}
+
+// ConsumeRefreshToken atomically marks the session whose current token hash
+// is tokenHash as consumed, but only if it is not already consumed or
+// revoked. This makes consumption a compare-and-swap: if two requests race to
+// redeem the same token, the filter (consumedAt/revokedAt still zero) can
+// only match for one of them, so a concurrent replay is reliably detected
+// instead of both requests silently succeeding. Returns the resulting user
+// document and whether this call was the one that consumed the token.
+func (s *UserDBService) ConsumeRefreshToken(instanceID string, userID string, tokenHash string, now int64) (models.User, bool, error) {
+	objID, err := stringToObjectID(userID)
+	if err != nil {
+		return models.User{}, false, err
+	}
+
+	ctx, cancel := s.context()
+	defer cancel()
+
+	filter := bson.M{
+		"_id": objID,
+		"sessions": bson.M{
+			"$elemMatch": bson.M{
+				"tokenHash":  tokenHash,
+				"consumedAt": bson.M{"$in": bson.A{0, nil}},
+				"revokedAt":  bson.M{"$in": bson.A{0, nil}},
+			},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"sessions.$[session].consumedAt": now,
+			"sessions.$[session].lastUsedAt": now,
+			"timestamps.lastTokenRefresh":    now,
+		},
+	}
+	opts := options.FindOneAndUpdate().
+		SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"session.tokenHash": tokenHash}}}).
+		SetReturnDocument(options.After)
+
+	var user models.User
+	err = s.collection(instanceID).FindOneAndUpdate(ctx, filter, update, opts).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		// Either the token doesn't exist at all, or it was already
+		// consumed/revoked by a previous (possibly concurrent) call; the caller
+		// distinguishes the two by looking the session up in the current document.
+		current, getErr := s.GetUserByID(instanceID, userID)
+		return current, false, getErr
+	}
+	if err != nil {
+		return models.User{}, false, err
+	}
+	return user, true, nil
+}
+
+// IncrementFailedLoginAttempts atomically increments the account's failed
+// login counter and records the failure time, returning the updated user
+// document so the caller can decide whether the new count crosses the
+// lockout threshold. Using $inc instead of a read-modify-write ReplaceOne
+// keeps concurrent failed attempts against the same account from clobbering
+// each other's counter update.
+func (s *UserDBService) IncrementFailedLoginAttempts(instanceID string, userID string, now int64) (models.User, error) {
+	objID, err := stringToObjectID(userID)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	ctx, cancel := s.context()
+	defer cancel()
+
+	update := bson.M{
+		"$inc": bson.M{"account.failedLoginAttempts": 1},
+		"$set": bson.M{"account.lastFailedLoginAt": now},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var user models.User
+	err = s.collection(instanceID).FindOneAndUpdate(ctx, bson.M{"_id": objID}, update, opts).Decode(&user)
+	return user, err
+}
+
+// LockAccount atomically sets the account's lockout expiry, but only if it
+// is not already locked, so two concurrent requests that both cross the
+// lockout threshold at once don't repeatedly push the expiry back out.
+func (s *UserDBService) LockAccount(instanceID string, userID string, now int64, until int64) error {
+	objID, err := stringToObjectID(userID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.context()
+	defer cancel()
+
+	_, err = s.collection(instanceID).UpdateOne(ctx,
+		bson.M{
+			"_id": objID,
+			// lockedUntil is omitted from the document while it is zero, so a
+			// missing field also means "not locked".
+			"$or": bson.A{
+				bson.M{"account.lockedUntil": bson.M{"$lte": now}},
+				bson.M{"account.lockedUntil": bson.M{"$exists": false}},
+			},
+		},
+		bson.M{"$set": bson.M{"account.lockedUntil": until}},
+	)
+	return err
+}
+
+// ResetFailedLoginAttempts atomically clears an account's failed-login
+// bookkeeping, e.g. after a successful login.
+func (s *UserDBService) ResetFailedLoginAttempts(instanceID string, userID string) error {
+	objID, err := stringToObjectID(userID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.context()
+	defer cancel()
+
+	_, err = s.collection(instanceID).UpdateOne(ctx,
+		bson.M{"_id": objID},
+		bson.M{"$set": bson.M{
+			"account.failedLoginAttempts": 0,
+			"account.lastFailedLoginAt":   0,
+			"account.lockedUntil":         0,
+		}},
+	)
+	return err
+}
+
+// UpdateLoginTime sets the last login timestamp for the given user.
+func (s *UserDBService) UpdateLoginTime(instanceID string, userID string) error {
+	objID, err := stringToObjectID(userID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.context()
+	defer cancel()
+
+	_, err = s.collection(instanceID).UpdateOne(ctx,
+		bson.M{"_id": objID},
+		bson.M{"$set": bson.M{"timestamps.lastLogin": time.Now().Unix()}},
+	)
+	return err
+}
+
+// DeleteUser removes a user document by ID.
+func (s *UserDBService) DeleteUser(instanceID string, userID string) error {
+	objID, err := stringToObjectID(userID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.context()
+	defer cancel()
+
+	_, err = s.collection(instanceID).DeleteOne(ctx, bson.M{"_id": objID})
+	return err
+}
+
+// DeleteUnverfiedUsers removes accounts that were created before the given
+// cutoff and never confirmed their email address. Returns the number of
+// deleted documents.
+func (s *UserDBService) DeleteUnverfiedUsers(instanceID string, cutoff int64) (int64, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	res, err := s.collection(instanceID).DeleteMany(ctx, bson.M{
+		"account.accountConfirmedAt": 0,
+		"timestamps.createdAt":       bson.M{"$lt": cutoff},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
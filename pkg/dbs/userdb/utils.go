@@ -0,0 +1,7 @@
+package userdb
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+func stringToObjectID(id string) (primitive.ObjectID, error) {
+	return primitive.ObjectIDFromHex(id)
+}
@@ -0,0 +1,65 @@
+package messageclient
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	messaging_api "github.com/influenzanet/messaging-service/pkg/api/messaging_service"
+)
+
+// Config holds the settings needed to dial the messaging-service.
+type Config struct {
+	Addr       string        `yaml:"addr"`
+	Timeout    time.Duration `yaml:"timeout"`
+	UseTLS     bool          `yaml:"useTLS"`
+	CACertFile string        `yaml:"caCertFile"`
+}
+
+// Client is a thin wrapper around the messaging-service gRPC client that
+// implements models.MessagingServiceApiClient.
+type Client struct {
+	api     messaging_api.MessagingServiceApiClient
+	timeout time.Duration
+}
+
+// Connect dials the messaging-service according to conf and returns a
+// ready-to-use Client.
+func Connect(conf Config) (*Client, error) {
+	dialOption := grpc.WithInsecure()
+	if conf.UseTLS {
+		creds, err := credentials.NewClientTLSFromFile(conf.CACertFile, "")
+		if err != nil {
+			return nil, err
+		}
+		dialOption = grpc.WithTransportCredentials(creds)
+	}
+
+	conn, err := grpc.Dial(conf.Addr, dialOption)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		api:     messaging_api.NewMessagingServiceApiClient(conn),
+		timeout: conf.Timeout,
+	}, nil
+}
+
+// SendInstantEmail asks the messaging service to send a localized, templated
+// email to the given recipients right away.
+func (c *Client) SendInstantEmail(to []string, messageType string, instanceID string, language string, contentInfos map[string]string, useLowPrio bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	_, err := c.api.SendInstantEmail(ctx, &messaging_api.SendEmailReq{
+		To:                to,
+		MessageType:       messageType,
+		InstanceId:        instanceID,
+		PreferredLanguage: language,
+		ContentInfos:      contentInfos,
+		UseLowPrio:        useLowPrio,
+	})
+	return err
+}
@@ -0,0 +1,44 @@
+package models
+
+// Account holds the credentials and login-related state for a user.
+type Account struct {
+	Type               string `bson:"type"`
+	AccountID          string `bson:"accountID"`
+	AccountConfirmedAt int64  `bson:"accountConfirmedAt"`
+	Password           string `bson:"password"`
+	PreferredLanguage  string `bson:"preferredLanguage"`
+
+	// VerificationTokenHash is the SHA-256 digest of the most recently issued
+	// account-confirmation / verification token, used to enforce single use.
+	// Cleared once the token has been consumed.
+	VerificationTokenHash      string `bson:"verificationTokenHash,omitempty"`
+	VerificationTokenExpiresAt int64  `bson:"verificationTokenExpiresAt,omitempty"`
+	// LastVerificationEmailSentAt is used to rate-limit ResendVerificationEmail.
+	LastVerificationEmailSentAt int64 `bson:"lastVerificationEmailSentAt,omitempty"`
+
+	// TOTPSecret is the base32-encoded shared secret provisioned by EnableTOTP.
+	// It is set as soon as enrollment starts but only takes effect for login
+	// once TOTPConfirmedAt is non-zero.
+	TOTPSecret      string `bson:"totpSecret,omitempty"`
+	TOTPConfirmedAt int64  `bson:"totpConfirmedAt,omitempty"`
+	// TOTPRecoveryCodeHashes holds bcrypt hashes of unused recovery codes
+	// issued alongside TOTP enrollment, each usable exactly once in place of
+	// a TOTP code.
+	TOTPRecoveryCodeHashes []string `bson:"totpRecoveryCodeHashes,omitempty"`
+	// LastTOTPCounter is the RFC 6238 time-step counter of the most recently
+	// accepted TOTP code, used to reject replay of a code already used once
+	// within its validity window. Reset whenever TOTPSecret changes.
+	LastTOTPCounter uint64 `bson:"lastTotpCounter,omitempty"`
+
+	// FailedLoginAttempts counts consecutive failed LoginWithEmail attempts
+	// since the last successful login, used to apply backoff and lockout.
+	// It is reset to zero on successful login.
+	FailedLoginAttempts int `bson:"failedLoginAttempts,omitempty"`
+	// LastFailedLoginAt is the unix timestamp of the most recent failed
+	// login attempt, used to compute the backoff delay.
+	LastFailedLoginAt int64 `bson:"lastFailedLoginAt,omitempty"`
+	// LockedUntil is the unix timestamp until which the account rejects
+	// login attempts outright, regardless of password correctness. Zero
+	// means the account is not locked.
+	LockedUntil int64 `bson:"lockedUntil,omitempty"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// AuditEntry records a single security-relevant event against a user account
+// (or, for unauthenticated events like a failed login, the account that was
+// targeted).
+type AuditEntry struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	InstanceID string             `bson:"instanceID"`
+	UserID     string             `bson:"userID,omitempty"`
+	EventType  string             `bson:"eventType"`
+	Timestamp  int64              `bson:"timestamp"`
+	SourceIP   string             `bson:"sourceIP,omitempty"`
+	UserAgent  string             `bson:"userAgent,omitempty"`
+	Payload    map[string]string  `bson:"payload,omitempty"`
+}
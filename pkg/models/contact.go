@@ -0,0 +1,18 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// ContactInfo represents a single verifiable way of reaching the user (email, phone, ...).
+type ContactInfo struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Type        string             `bson:"type"`
+	Email       string             `bson:"email,omitempty"`
+	ConfirmedAt int64              `bson:"confirmedAt"`
+}
+
+// ContactPreferences holds the user's preferences about which contact channels
+// may be used for which kind of message.
+type ContactPreferences struct {
+	SubscribedToNewsletter bool     `bson:"subscribedToNewsletter"`
+	SendNewsletterTo       []string `bson:"sendNewsletterTo"`
+}
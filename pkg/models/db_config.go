@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// DBConfig holds the connection settings for a single MongoDB database.
+type DBConfig struct {
+	URI             string `yaml:"uri"`
+	Timeout         int    `yaml:"timeout"`
+	IdleConnTimeout int    `yaml:"idleConnTimeout"`
+	MaxPoolSize     uint64 `yaml:"maxPoolSize"`
+	DBNamePrefix    string `yaml:"dbNamePrefix"`
+}
+
+// JWTConfig holds the settings used to issue and validate access tokens.
+type JWTConfig struct {
+	SigningKey          string        `yaml:"signingKey"`
+	SigningAlgorithm    string        `yaml:"signingAlgorithm"`
+	Issuer              string        `yaml:"issuer"`
+	TokenExpiryInterval time.Duration `yaml:"tokenExpiryInterval"`
+	TokenMinimumAgeMin  time.Duration `yaml:"tokenMinimumAgeMin"`
+}
+
+// APIClients bundles the gRPC clients the service uses to talk to other
+// influenzanet services.
+type APIClients struct {
+	MessagingService MessagingServiceApiClient
+}
+
+// MessagingServiceApiClient is the subset of the messaging-service gRPC client
+// that the user-management-service depends on. Defined as an interface here
+// so that pkg/messageclient can provide the real implementation without
+// pkg/models importing the generated gRPC stubs.
+type MessagingServiceApiClient interface {
+	SendInstantEmail(to []string, messageType string, instanceID string, language string, contentInfos map[string]string, useLowPrio bool) error
+}
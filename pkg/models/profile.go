@@ -0,0 +1,24 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/influenzanet/user-management-service/pkg/api"
+)
+
+// Profile represents one of the participant profiles a user account can manage.
+type Profile struct {
+	ID                 primitive.ObjectID `bson:"_id,omitempty"`
+	Nickname           string             `bson:"nickname"`
+	AvatarID           string             `bson:"avatarId"`
+	ConsentConfirmedAt int64              `bson:"consentConfirmedAt"`
+}
+
+// ToAPI converts a Profile into its gRPC representation.
+func (p Profile) ToAPI() *api.Profile {
+	return &api.Profile{
+		Id:       p.ID.Hex(),
+		Nickname: p.Nickname,
+		AvatarId: p.AvatarID,
+	}
+}
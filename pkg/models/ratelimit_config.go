@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+// RateLimitConfig holds the settings for login brute-force throttling.
+type RateLimitConfig struct {
+	MaxFailedAttempts int           `yaml:"maxFailedAttempts"`
+	LockoutDuration   time.Duration `yaml:"lockoutDuration"`
+}
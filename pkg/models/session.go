@@ -0,0 +1,54 @@
+package models
+
+// RefreshSession is one refresh token issued for a login session. Tokens are
+// rotated on every use: ParentTokenID links a token back to the one it
+// replaced, forming a lineage per SessionID. Only the latest token in a
+// lineage is ever valid; presenting a ConsumedAt token again is treated as a
+// sign of compromise (see User.RevokeSessionsBySessionID).
+type RefreshSession struct {
+	SessionID         string `bson:"sessionId"`
+	TokenID           string `bson:"tokenId"`
+	TokenHash         string `bson:"tokenHash"`
+	ParentTokenID     string `bson:"parentTokenId,omitempty"`
+	DeviceFingerprint string `bson:"deviceFingerprint,omitempty"`
+	IssuedAt          int64  `bson:"issuedAt"`
+	LastUsedAt        int64  `bson:"lastUsedAt"`
+	ConsumedAt        int64  `bson:"consumedAt,omitempty"`
+	RevokedAt         int64  `bson:"revokedAt,omitempty"`
+}
+
+// IsActive reports whether the session's current token may still be redeemed.
+func (r RefreshSession) IsActive() bool {
+	return r.ConsumedAt == 0 && r.RevokedAt == 0
+}
+
+// FindSessionByTokenHash returns the session record whose current token hash
+// matches, if any.
+func (u User) FindSessionByTokenHash(tokenHash string) (RefreshSession, bool) {
+	for _, session := range u.Sessions {
+		if session.TokenHash == tokenHash {
+			return session, true
+		}
+	}
+	return RefreshSession{}, false
+}
+
+// RevokeSessionsBySessionID marks every token in the given session's lineage
+// as revoked, forcing that device to log in again. Used both for an explicit
+// RevokeSession call and as the compromise response to refresh-token reuse.
+func (u *User) RevokeSessionsBySessionID(sessionID string, at int64) {
+	for i := range u.Sessions {
+		if u.Sessions[i].SessionID == sessionID && u.Sessions[i].RevokedAt == 0 {
+			u.Sessions[i].RevokedAt = at
+		}
+	}
+}
+
+// RevokeAllSessions marks every session as revoked.
+func (u *User) RevokeAllSessions(at int64) {
+	for i := range u.Sessions {
+		if u.Sessions[i].RevokedAt == 0 {
+			u.Sessions[i].RevokedAt = at
+		}
+	}
+}
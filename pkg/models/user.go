@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/influenzanet/user-management-service/pkg/api"
+)
+
+// Timestamps tracks the main lifecycle events of a user document.
+type Timestamps struct {
+	CreatedAt        int64 `bson:"createdAt"`
+	UpdatedAt        int64 `bson:"updatedAt"`
+	LastLogin        int64 `bson:"lastLogin"`
+	LastTokenRefresh int64 `bson:"lastTokenRefresh"`
+}
+
+// User is the main per-account document stored in the user DB.
+type User struct {
+	ID                 primitive.ObjectID `bson:"_id,omitempty"`
+	Account            Account            `bson:"account"`
+	Roles              []string           `bson:"roles"`
+	Profiles           []Profile          `bson:"profiles"`
+	ContactInfos       []ContactInfo      `bson:"contactInfos"`
+	ContactPreferences ContactPreferences `bson:"contactPreferences"`
+	Timestamps         Timestamps         `bson:"timestamps"`
+	Sessions           []RefreshSession   `bson:"sessions"`
+}
+
+// AddNewEmail appends a new (optionally pre-confirmed) email contact info entry.
+func (u *User) AddNewEmail(email string, confirmed bool) {
+	ci := ContactInfo{
+		ID:    primitive.NewObjectID(),
+		Type:  "email",
+		Email: email,
+	}
+	if confirmed {
+		ci.ConfirmedAt = u.Timestamps.CreatedAt
+	}
+	u.ContactInfos = append(u.ContactInfos, ci)
+}
+
+// FindProfile returns the profile with the given hex ID, if it exists.
+func (u User) FindProfile(profileID string) (Profile, error) {
+	for _, p := range u.Profiles {
+		if p.ID.Hex() == profileID {
+			return p, nil
+		}
+	}
+	return Profile{}, errors.New("profile not found")
+}
+
+// ToAPI converts a User into its gRPC representation.
+func (u User) ToAPI() *api.User {
+	profiles := make([]*api.Profile, len(u.Profiles))
+	for i, p := range u.Profiles {
+		profiles[i] = p.ToAPI()
+	}
+	return &api.User{
+		Account: &api.Account{
+			AccountId:          u.Account.AccountID,
+			AccountConfirmedAt: u.Account.AccountConfirmedAt,
+			PreferredLanguage:  u.Account.PreferredLanguage,
+		},
+		Profiles: profiles,
+	}
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// VerificationConfig holds the settings for account-confirmation / email
+// verification tokens.
+type VerificationConfig struct {
+	SigningKey        string        `yaml:"signingKey"`
+	TokenExpiry       time.Duration `yaml:"tokenExpiry"`
+	ResendMinInterval time.Duration `yaml:"resendMinInterval"`
+}
+
+// ReauthConfig holds the settings for the short-lived, scope-limited
+// sensitive-action tokens issued by Reauthenticate.
+type ReauthConfig struct {
+	SigningKey  string        `yaml:"signingKey"`
+	TokenExpiry time.Duration `yaml:"tokenExpiry"`
+}
+
+// ChallengeConfig holds the settings for the short-lived login challenge
+// tokens issued by LoginWithEmail when a second factor is required.
+type ChallengeConfig struct {
+	SigningKey  string        `yaml:"signingKey"`
+	TokenExpiry time.Duration `yaml:"tokenExpiry"`
+}
@@ -0,0 +1,25 @@
+package pwhash
+
+import "golang.org/x/crypto/bcrypt"
+
+// HashPassword returns the bcrypt hash of the given plain-text password.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// ComparePasswordWithHash reports whether the plain-text password matches the
+// given bcrypt hash.
+func ComparePasswordWithHash(hash string, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if err == nil {
+		return true, nil
+	}
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	}
+	return false, err
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/influenzanet/user-management-service/pkg/api"
+	"github.com/influenzanet/user-management-service/pkg/audit"
+	"github.com/influenzanet/user-management-service/pkg/models"
+	"github.com/influenzanet/user-management-service/pkg/pwhash"
+	"github.com/influenzanet/user-management-service/pkg/tokens"
+	utils "github.com/influenzanet/user-management-service/utils"
+)
+
+// Reauthenticate checks the caller's current password and, on success, issues
+// a short-lived, scope-limited sensitive-action token. Password change, email
+// change, account deletion and role-management all require one of these
+// tokens instead of just a normal access token. It shares the same
+// per-IP/per-account throttle as LoginWithEmail, since a caller presenting a
+// valid (possibly stolen) access token would otherwise be able to brute-force
+// the account's password here without ever triggering the login lockout.
+func (s *userManagementServer) Reauthenticate(ctx context.Context, req *api.ReauthRequest) (*api.SensitiveActionToken, error) {
+	if req == nil || utils.IsTokenEmpty(req.Token) || req.Scope == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+	if req.Password == "" && req.Otp == "" {
+		return nil, status.Error(codes.InvalidArgument, "password or otp required")
+	}
+	sourceIP := sourceIPFromContext(ctx)
+	userAgent := userAgentFromContext(ctx)
+
+	if allowed, retryAfter, err := s.loginThrottle.Allow(sourceIP); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	} else if !allowed {
+		return nil, status.Errorf(codes.ResourceExhausted, "too many failed attempts, try again in %s", retryAfter.Round(time.Second))
+	}
+
+	user, err := s.userDBservice.GetUserByID(req.Token.InstanceId, req.Token.Id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "user not found")
+	}
+	if user.Account.LockedUntil > time.Now().Unix() {
+		return nil, status.Error(codes.ResourceExhausted, "account temporarily locked due to too many failed attempts")
+	}
+
+	if req.Password != "" {
+		match, err := pwhash.ComparePasswordWithHash(user.Account.Password, req.Password)
+		if err != nil || !match {
+			s.recordLoginFailure(req.Token.InstanceId, user.ID.Hex(), sourceIP, userAgent)
+			if err := s.registerFailedLogin(req.Token.InstanceId, user); err != nil {
+				log.Printf("Reauthenticate: failed to persist failed attempt: %s", err.Error())
+			}
+			return nil, status.Error(codes.Unauthenticated, "invalid password")
+		}
+	} else {
+		// OTP-based reauthentication is not implemented yet; reject explicitly
+		// rather than silently accepting an unverified challenge.
+		return nil, status.Error(codes.Unimplemented, "otp reauthentication not supported")
+	}
+
+	s.resetLoginThrottle(req.Token.InstanceId, user)
+
+	scope := tokens.Scope(req.Scope)
+	token, expiresAt, err := s.reauthTokens.Issue(req.Token.InstanceId, user.ID.Hex(), scope)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.SensitiveActionToken{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// requireSensitiveActionToken validates that reauthToken is a sensitive-action
+// token issued to userID for the given scope.
+func (s *userManagementServer) requireSensitiveActionToken(reauthToken string, userID string, scope tokens.Scope) error {
+	if reauthToken == "" {
+		return status.Error(codes.Unauthenticated, "reauthentication required")
+	}
+	if _, err := s.reauthTokens.Validate(reauthToken, userID, scope); err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	return nil
+}
+
+func (s *userManagementServer) ChangePassword(ctx context.Context, req *api.ChangePasswordMsg) (*api.ServiceStatus, error) {
+	if req == nil || utils.IsTokenEmpty(req.Token) || req.NewPassword == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+	if !utils.CheckPasswordFormat(req.NewPassword) {
+		return nil, status.Error(codes.InvalidArgument, "password too weak")
+	}
+	if err := s.requireSensitiveActionToken(req.ReauthToken, req.Token.Id, tokens.ScopePasswordChange); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userDBservice.GetUserByID(req.Token.InstanceId, req.Token.Id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "user not found")
+	}
+
+	newHash, err := pwhash.HashPassword(req.NewPassword)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	user.Account.Password = newHash
+
+	if _, err := s.userDBservice.UpdateUser(req.Token.InstanceId, user); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.auditWriter.Emit(models.AuditEntry{
+		InstanceID: req.Token.InstanceId,
+		UserID:     req.Token.Id,
+		EventType:  audit.EventPasswordChanged,
+		SourceIP:   sourceIPFromContext(ctx),
+		UserAgent:  userAgentFromContext(ctx),
+	})
+
+	return &api.ServiceStatus{Status: api.ServiceStatus_NORMAL, Msg: "password changed"}, nil
+}
+
+func (s *userManagementServer) ChangeEmail(ctx context.Context, req *api.ChangeEmailMsg) (*api.ServiceStatus, error) {
+	if req == nil || utils.IsTokenEmpty(req.Token) || req.NewEmail == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+	if !utils.CheckEmailFormat(req.NewEmail) {
+		return nil, status.Error(codes.InvalidArgument, "email not valid")
+	}
+	if err := s.requireSensitiveActionToken(req.ReauthToken, req.Token.Id, tokens.ScopeEmailChange); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userDBservice.GetUserByID(req.Token.InstanceId, req.Token.Id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "user not found")
+	}
+
+	user.Account.AccountID = req.NewEmail
+	user.Account.AccountConfirmedAt = 0
+	user.AddNewEmail(req.NewEmail, false)
+
+	if _, err := s.userDBservice.UpdateUser(req.Token.InstanceId, user); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err := s.issueAndSendVerificationToken(req.Token.InstanceId, user); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.auditWriter.Emit(models.AuditEntry{
+		InstanceID: req.Token.InstanceId,
+		UserID:     req.Token.Id,
+		EventType:  audit.EventEmailChanged,
+		SourceIP:   sourceIPFromContext(ctx),
+		UserAgent:  userAgentFromContext(ctx),
+	})
+
+	return &api.ServiceStatus{Status: api.ServiceStatus_NORMAL, Msg: "email changed, verification required"}, nil
+}
+
+func (s *userManagementServer) DeleteAccount(ctx context.Context, req *api.DeleteAccountMsg) (*api.ServiceStatus, error) {
+	if req == nil || utils.IsTokenEmpty(req.Token) {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+	if err := s.requireSensitiveActionToken(req.ReauthToken, req.Token.Id, tokens.ScopeAccountDeletion); err != nil {
+		return nil, err
+	}
+
+	if err := s.userDBservice.DeleteUser(req.Token.InstanceId, req.Token.Id); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.auditWriter.Emit(models.AuditEntry{
+		InstanceID: req.Token.InstanceId,
+		UserID:     req.Token.Id,
+		EventType:  audit.EventAccountDeleted,
+		SourceIP:   sourceIPFromContext(ctx),
+		UserAgent:  userAgentFromContext(ctx),
+	})
+
+	return &api.ServiceStatus{Status: api.ServiceStatus_NORMAL, Msg: "account deleted"}, nil
+}
+
+func (s *userManagementServer) ChangeUserRoles(ctx context.Context, req *api.ChangeUserRolesMsg) (*api.ServiceStatus, error) {
+	if req == nil || utils.IsTokenEmpty(req.Token) || req.UserId == "" || len(req.NewRoles) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+	if !hasRole(req.Token.Roles, "ADMIN") {
+		return nil, status.Error(codes.PermissionDenied, "admin role required")
+	}
+	if err := s.requireSensitiveActionToken(req.ReauthToken, req.Token.Id, tokens.ScopeRoleManagement); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userDBservice.GetUserByID(req.Token.InstanceId, req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "user not found")
+	}
+	user.Roles = req.NewRoles
+
+	if _, err := s.userDBservice.UpdateUser(req.Token.InstanceId, user); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.auditWriter.Emit(models.AuditEntry{
+		InstanceID: req.Token.InstanceId,
+		UserID:     req.UserId,
+		EventType:  audit.EventRoleChanged,
+		SourceIP:   sourceIPFromContext(ctx),
+		UserAgent:  userAgentFromContext(ctx),
+	})
+
+	return &api.ServiceStatus{Status: api.ServiceStatus_NORMAL, Msg: "roles updated"}, nil
+}
+
+// UnlockAccount clears a lockout placed by the login brute-force throttle,
+// letting the account owner sign in again before LockedUntil would otherwise
+// expire on its own.
+func (s *userManagementServer) UnlockAccount(ctx context.Context, req *api.UnlockAccountMsg) (*api.ServiceStatus, error) {
+	if req == nil || utils.IsTokenEmpty(req.Token) || req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+	if !hasRole(req.Token.Roles, "ADMIN") {
+		return nil, status.Error(codes.PermissionDenied, "admin role required")
+	}
+
+	user, err := s.userDBservice.GetUserByID(req.Token.InstanceId, req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "user not found")
+	}
+
+	s.resetLoginThrottle(req.Token.InstanceId, user)
+
+	s.auditWriter.Emit(models.AuditEntry{
+		InstanceID: req.Token.InstanceId,
+		UserID:     req.UserId,
+		EventType:  audit.EventAccountUnlocked,
+		SourceIP:   sourceIPFromContext(ctx),
+		UserAgent:  userAgentFromContext(ctx),
+	})
+
+	return &api.ServiceStatus{Status: api.ServiceStatus_NORMAL, Msg: "account unlocked"}, nil
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/influenzanet/user-management-service/pkg/api"
+	"github.com/influenzanet/user-management-service/pkg/audit"
+	"github.com/influenzanet/user-management-service/pkg/models"
+	utils "github.com/influenzanet/user-management-service/utils"
+)
+
+// GetAuditLog returns the caller's own audit history.
+func (s *userManagementServer) GetAuditLog(ctx context.Context, req *api.GetAuditLogMsg) (*api.AuditLogResponse, error) {
+	if req == nil || utils.IsTokenEmpty(req.Token) {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+
+	entries, total, err := s.auditStore.Query(
+		req.Token.InstanceId,
+		audit.Filter{UserID: req.Token.Id},
+		audit.Pagination{Limit: req.Limit, Offset: req.Offset},
+	)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.AuditLogResponse{Entries: toAuditLogEntries(entries), Total: total}, nil
+}
+
+// QueryAuditLog lets an admin search the audit log of any user on the
+// instance.
+func (s *userManagementServer) QueryAuditLog(ctx context.Context, req *api.QueryAuditLogMsg) (*api.AuditLogResponse, error) {
+	if req == nil || utils.IsTokenEmpty(req.Token) {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+	if !hasRole(req.Token.Roles, "ADMIN") {
+		return nil, status.Error(codes.PermissionDenied, "admin role required")
+	}
+
+	entries, total, err := s.auditStore.Query(
+		req.Token.InstanceId,
+		audit.Filter{
+			UserID:    req.UserId,
+			EventType: req.EventType,
+			Since:     req.Since,
+			Until:     req.Until,
+		},
+		audit.Pagination{Limit: req.Limit, Offset: req.Offset},
+	)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.AuditLogResponse{Entries: toAuditLogEntries(entries), Total: total}, nil
+}
+
+func toAuditLogEntries(entries []models.AuditEntry) []*api.AuditLogEntry {
+	out := make([]*api.AuditLogEntry, len(entries))
+	for i, entry := range entries {
+		out[i] = &api.AuditLogEntry{
+			UserId:    entry.UserID,
+			EventType: entry.EventType,
+			Timestamp: entry.Timestamp,
+			SourceIp:  entry.SourceIP,
+			UserAgent: entry.UserAgent,
+		}
+	}
+	return out
+}
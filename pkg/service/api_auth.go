@@ -11,8 +11,10 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/influenzanet/user-management-service/pkg/api"
+	"github.com/influenzanet/user-management-service/pkg/audit"
 	"github.com/influenzanet/user-management-service/pkg/models"
 	"github.com/influenzanet/user-management-service/pkg/pwhash"
+	"github.com/influenzanet/user-management-service/pkg/tokens"
 	utils "github.com/influenzanet/user-management-service/utils"
 )
 
@@ -29,21 +31,70 @@ func (s *userManagementServer) LoginWithEmail(ctx context.Context, req *api.Logi
 	if instanceID == "" {
 		instanceID = "default"
 	}
-	user, err := s.userDBservice.GetUserByEmail(instanceID, req.Email)
+	sourceIP := sourceIPFromContext(ctx)
+	userAgent := userAgentFromContext(ctx)
+
+	if allowed, retryAfter, err := s.loginThrottle.Allow(sourceIP); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	} else if !allowed {
+		return nil, status.Errorf(codes.ResourceExhausted, "too many failed attempts, try again in %s", retryAfter.Round(time.Second))
+	}
 
+	user, err := s.userDBservice.GetUserByEmail(instanceID, req.Email)
 	if err != nil {
+		s.recordLoginFailure(instanceID, "", sourceIP, userAgent)
 		return nil, status.Error(codes.InvalidArgument, "invalid username and/or password")
 	}
 
+	now := time.Now().Unix()
+	if user.Account.LockedUntil > now {
+		return nil, status.Error(codes.ResourceExhausted, "account temporarily locked due to too many failed attempts")
+	}
+	if delay := loginBackoffDelay(user.Account.FailedLoginAttempts); delay > 0 && now < user.Account.LastFailedLoginAt+int64(delay.Seconds()) {
+		return nil, status.Error(codes.ResourceExhausted, "too many attempts, please try again shortly")
+	}
+
 	match, err := pwhash.ComparePasswordWithHash(user.Account.Password, req.Password)
 	if err != nil || !match {
+		s.recordLoginFailure(instanceID, user.ID.Hex(), sourceIP, userAgent)
+		if err := s.registerFailedLogin(instanceID, user); err != nil {
+			log.Printf("LoginWithEmail: failed to persist failed attempt: %s", err.Error())
+		}
 		return nil, status.Error(codes.InvalidArgument, "invalid username and/or password")
 	}
 
+	s.resetLoginThrottle(instanceID, user)
+
+	if user.Account.TOTPConfirmedAt > 0 {
+		challengeToken, err := s.loginChallengeTokens.Issue(instanceID, user.ID.Hex())
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		return &api.UserAuthInfo{
+			InstanceId:           instanceID,
+			SecondFactorRequired: true,
+			ChallengeToken:       challengeToken,
+		}, nil
+	}
+
 	if err := s.userDBservice.UpdateLoginTime(instanceID, user.ID.Hex()); err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	s.auditWriter.Emit(models.AuditEntry{
+		InstanceID: instanceID,
+		UserID:     user.ID.Hex(),
+		EventType:  audit.EventLoginSuccess,
+		SourceIP:   sourceIP,
+		UserAgent:  userAgent,
+	})
+
+	return buildUserAuthInfo(instanceID, user), nil
+}
+
+// buildUserAuthInfo assembles the full authenticated-session response shared
+// by LoginWithEmail and LoginWithSecondFactor.
+func buildUserAuthInfo(instanceID string, user models.User) *api.UserAuthInfo {
 	var username string
 	if len(user.Roles) > 1 || len(user.Roles) == 1 && user.Roles[0] != "PARTICIPANT" {
 		username = user.Account.AccountID
@@ -51,7 +102,7 @@ func (s *userManagementServer) LoginWithEmail(ctx context.Context, req *api.Logi
 
 	apiUser := user.ToAPI()
 
-	response := &api.UserAuthInfo{
+	return &api.UserAuthInfo{
 		UserId:            user.ID.Hex(),
 		Roles:             user.Roles,
 		InstanceId:        instanceID,
@@ -61,8 +112,6 @@ func (s *userManagementServer) LoginWithEmail(ctx context.Context, req *api.Logi
 		SelectedProfile:   apiUser.Profiles[0],
 		PreferredLanguage: apiUser.Account.PreferredLanguage,
 	}
-	return response, nil
-
 }
 
 func (s *userManagementServer) SignupWithEmail(ctx context.Context, req *api.SignupWithEmailMsg) (*api.UserAuthInfo, error) {
@@ -116,11 +165,24 @@ func (s *userManagementServer) SignupWithEmail(ctx context.Context, req *api.Sig
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
+	newUser.ID, err = primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err := s.issueAndSendVerificationToken(instanceID, newUser); err != nil {
+		// The account was created successfully; CleanUpUnverifiedUsers acts as
+		// the fallback if the confirmation email never made it out.
+		log.Printf("SignupWithEmail: failed to send verification email: %s", err.Error())
+	}
 
-	log.Println("TODO: generate account confirmation token for newly created user")
-	log.Println("TODO: send email for newly created user")
-	// TODO: generate email confirmation token
-	// TODO: send email with confirmation request
+	s.auditWriter.Emit(models.AuditEntry{
+		InstanceID: instanceID,
+		UserID:     id,
+		EventType:  audit.EventSignup,
+		SourceIP:   sourceIPFromContext(ctx),
+		UserAgent:  userAgentFromContext(ctx),
+	})
 
 	var username string
 	if len(newUser.Roles) > 1 || len(newUser.Roles) == 1 && newUser.Roles[0] != "PARTICIPANT" {
@@ -142,80 +204,147 @@ func (s *userManagementServer) SignupWithEmail(ctx context.Context, req *api.Sig
 	return response, nil
 }
 
-func (s *userManagementServer) CheckRefreshToken(ctx context.Context, req *api.RefreshTokenRequest) (*api.ServiceStatus, error) {
-	if req == nil || req.RefreshToken == "" || req.UserId == "" || req.InstanceId == "" {
+func (s *userManagementServer) SwitchProfile(ctx context.Context, req *api.ProfileRequest) (*api.UserAuthInfo, error) {
+	if req == nil || utils.IsTokenEmpty(req.Token) || req.Profile == nil {
 		return nil, status.Error(codes.InvalidArgument, "missing argument")
 	}
-	user, err := s.userDBservice.GetUserByID(req.InstanceId, req.UserId)
+	user, err := s.userDBservice.GetUserByID(req.Token.InstanceId, req.Token.Id)
 	if err != nil {
 		return nil, status.Error(codes.Internal, "user not found")
 	}
 
-	err = user.RemoveRefreshToken(req.RefreshToken)
+	profile, err := user.FindProfile(req.Profile.Id)
 	if err != nil {
-		return nil, status.Error(codes.Internal, "token not found")
+		return nil, status.Error(codes.Internal, "profile not found")
 	}
-	user.Timestamps.LastTokenRefresh = time.Now().Unix()
 
-	user, err = s.userDBservice.UpdateUser(req.InstanceId, user)
+	apiUser := user.ToAPI()
+
+	response := &api.UserAuthInfo{
+		UserId:            user.ID.Hex(),
+		Roles:             user.Roles,
+		InstanceId:        req.Token.InstanceId,
+		AccountConfirmed:  apiUser.Account.AccountConfirmedAt > 0,
+		AccountId:         apiUser.Account.AccountId,
+		Profiles:          apiUser.Profiles,
+		SelectedProfile:   profile.ToAPI(),
+		PreferredLanguage: apiUser.Account.PreferredLanguage,
+	}
+
+	s.auditWriter.Emit(models.AuditEntry{
+		InstanceID: req.Token.InstanceId,
+		UserID:     user.ID.Hex(),
+		EventType:  audit.EventProfileSwitched,
+		SourceIP:   sourceIPFromContext(ctx),
+		UserAgent:  userAgentFromContext(ctx),
+	})
+
+	return response, nil
+}
+
+// issueAndSendVerificationToken mints a new email-confirmation token for user,
+// persists its hash for single-use enforcement, and dispatches the
+// confirmation email through the messaging service.
+func (s *userManagementServer) issueAndSendVerificationToken(instanceID string, user models.User) error {
+	token, tokenHash, expiresAt, err := s.verificationTokens.Generate(instanceID, user.Account.AccountID, tokens.PurposeEmailConfirmation)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return err
 	}
 
-	return &api.ServiceStatus{
-		Status: api.ServiceStatus_NORMAL,
-		Msg:    "refresh token removed",
-	}, nil
+	user.Account.VerificationTokenHash = tokenHash
+	user.Account.VerificationTokenExpiresAt = expiresAt
+	user.Account.LastVerificationEmailSentAt = time.Now().Unix()
+	if _, err := s.userDBservice.UpdateUser(instanceID, user); err != nil {
+		return err
+	}
+
+	return s.clients.MessagingService.SendInstantEmail(
+		[]string{user.Account.AccountID},
+		"verify-email",
+		instanceID,
+		user.Account.PreferredLanguage,
+		map[string]string{"token": token},
+		false,
+	)
 }
 
-func (s *userManagementServer) TokenRefreshed(ctx context.Context, req *api.RefreshTokenRequest) (*api.ServiceStatus, error) {
-	if req == nil || req.RefreshToken == "" || req.UserId == "" || req.InstanceId == "" {
+func (s *userManagementServer) VerifyAccount(ctx context.Context, req *api.VerifyAccountMsg) (*api.ServiceStatus, error) {
+	if req == nil || req.Token == "" {
 		return nil, status.Error(codes.InvalidArgument, "missing argument")
 	}
 
-	user, err := s.userDBservice.GetUserByID(req.InstanceId, req.UserId)
+	instanceID := req.InstanceId
+	if instanceID == "" {
+		instanceID = "default"
+	}
+
+	claims, err := s.verificationTokens.Validate(req.Token)
 	if err != nil {
-		return nil, status.Error(codes.Internal, "user not found")
+		return nil, status.Error(codes.InvalidArgument, "invalid or expired token")
 	}
-	user.AddRefreshToken(req.RefreshToken)
-	user.Timestamps.LastTokenRefresh = time.Now().Unix()
 
-	user, err = s.userDBservice.UpdateUser(req.InstanceId, user)
+	user, err := s.userDBservice.GetUserByEmail(instanceID, claims.AccountID)
 	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid or expired token")
+	}
+
+	if user.Account.VerificationTokenHash == "" || user.Account.VerificationTokenHash != tokens.Hash(req.Token) {
+		return nil, status.Error(codes.InvalidArgument, "invalid or expired token")
+	}
+
+	user.Account.AccountConfirmedAt = time.Now().Unix()
+	user.Account.VerificationTokenHash = ""
+	user.Account.VerificationTokenExpiresAt = 0
+
+	if _, err := s.userDBservice.UpdateUser(instanceID, user); err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	s.auditWriter.Emit(models.AuditEntry{
+		InstanceID: instanceID,
+		UserID:     user.ID.Hex(),
+		EventType:  audit.EventAccountConfirmed,
+		SourceIP:   sourceIPFromContext(ctx),
+		UserAgent:  userAgentFromContext(ctx),
+	})
+
 	return &api.ServiceStatus{
 		Status: api.ServiceStatus_NORMAL,
-		Msg:    "token refresh time updated",
+		Msg:    "account confirmed",
 	}, nil
 }
 
-func (s *userManagementServer) SwitchProfile(ctx context.Context, req *api.ProfileRequest) (*api.UserAuthInfo, error) {
-	if req == nil || utils.IsTokenEmpty(req.Token) || req.Profile == nil {
+func (s *userManagementServer) ResendVerificationEmail(ctx context.Context, req *api.ResendVerificationEmailMsg) (*api.ServiceStatus, error) {
+	if req == nil || req.Email == "" {
 		return nil, status.Error(codes.InvalidArgument, "missing argument")
 	}
-	user, err := s.userDBservice.GetUserByID(req.Token.InstanceId, req.Token.Id)
-	if err != nil {
-		return nil, status.Error(codes.Internal, "user not found")
+
+	instanceID := req.InstanceId
+	if instanceID == "" {
+		instanceID = "default"
 	}
 
-	profile, err := user.FindProfile(req.Profile.Id)
+	user, err := s.userDBservice.GetUserByEmail(instanceID, req.Email)
 	if err != nil {
-		return nil, status.Error(codes.Internal, "profile not found")
+		// Do not leak whether the account exists.
+		return &api.ServiceStatus{Status: api.ServiceStatus_NORMAL, Msg: "verification email sent if account exists"}, nil
 	}
 
-	apiUser := user.ToAPI()
+	if user.Account.AccountConfirmedAt > 0 {
+		return &api.ServiceStatus{Status: api.ServiceStatus_NORMAL, Msg: "verification email sent if account exists"}, nil
+	}
 
-	response := &api.UserAuthInfo{
-		UserId:            user.ID.Hex(),
-		Roles:             user.Roles,
-		InstanceId:        req.Token.InstanceId,
-		AccountConfirmed:  apiUser.Account.AccountConfirmedAt > 0,
-		AccountId:         apiUser.Account.AccountId,
-		Profiles:          apiUser.Profiles,
-		SelectedProfile:   profile.ToAPI(),
-		PreferredLanguage: apiUser.Account.PreferredLanguage,
+	minInterval := s.verificationConfig.ResendMinInterval
+	if minInterval > 0 && time.Now().Unix()-user.Account.LastVerificationEmailSentAt < int64(minInterval.Seconds()) {
+		return nil, status.Error(codes.ResourceExhausted, "please wait before requesting another verification email")
 	}
-	return response, nil
-}
\ No newline at end of file
+
+	if err := s.issueAndSendVerificationToken(instanceID, user); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.ServiceStatus{
+		Status: api.ServiceStatus_NORMAL,
+		Msg:    "verification email sent if account exists",
+	}, nil
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/influenzanet/user-management-service/pkg/api"
+	"github.com/influenzanet/user-management-service/pkg/audit"
+	"github.com/influenzanet/user-management-service/pkg/models"
+	"github.com/influenzanet/user-management-service/pkg/tokens"
+	utils "github.com/influenzanet/user-management-service/utils"
+)
+
+// CheckRefreshToken validates a presented refresh token and consumes it,
+// marking the session's rotation lineage one step forward. A token that was
+// already consumed is treated as a sign of compromise: the whole session
+// (every token ever issued in its rotation lineage) is revoked and the
+// caller must log in again.
+func (s *userManagementServer) CheckRefreshToken(ctx context.Context, req *api.RefreshTokenRequest) (*api.ServiceStatus, error) {
+	if req == nil || req.RefreshToken == "" || req.UserId == "" || req.InstanceId == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+	tokenHash := tokens.Hash(req.RefreshToken)
+	now := time.Now().Unix()
+
+	user, consumed, err := s.userDBservice.ConsumeRefreshToken(req.InstanceId, req.UserId, tokenHash, now)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	session, found := user.FindSessionByTokenHash(tokenHash)
+	if !found {
+		return nil, status.Error(codes.NotFound, "refresh token not found")
+	}
+
+	if !consumed {
+		// The token exists but was already consumed or revoked (by this exact
+		// race or an earlier request): the lineage may have been stolen. Force
+		// logout of the whole session as a precaution.
+		user.RevokeSessionsBySessionID(session.SessionID, now)
+		if _, err := s.userDBservice.UpdateUser(req.InstanceId, user); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		s.auditWriter.Emit(models.AuditEntry{
+			InstanceID: req.InstanceId,
+			UserID:     req.UserId,
+			EventType:  audit.EventTokenReuseDetected,
+			SourceIP:   sourceIPFromContext(ctx),
+			UserAgent:  userAgentFromContext(ctx),
+		})
+		return nil, status.Error(codes.PermissionDenied, "refresh token reuse detected, session revoked")
+	}
+
+	return &api.ServiceStatus{
+		Status: api.ServiceStatus_NORMAL,
+		Msg:    "refresh token consumed",
+	}, nil
+}
+
+// TokenRefreshed records a newly issued refresh token, rotating it into the
+// rotation lineage of req.PreviousToken when present, or starting a new
+// session otherwise.
+func (s *userManagementServer) TokenRefreshed(ctx context.Context, req *api.RefreshTokenRequest) (*api.ServiceStatus, error) {
+	if req == nil || req.RefreshToken == "" || req.UserId == "" || req.InstanceId == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+
+	user, err := s.userDBservice.GetUserByID(req.InstanceId, req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "user not found")
+	}
+
+	sessionID := primitive.NewObjectID().Hex()
+	var parentTokenID string
+	if req.PreviousToken != "" {
+		if parent, found := user.FindSessionByTokenHash(tokens.Hash(req.PreviousToken)); found {
+			sessionID = parent.SessionID
+			parentTokenID = parent.TokenID
+		}
+	}
+
+	now := time.Now().Unix()
+	user.Sessions = append(user.Sessions, models.RefreshSession{
+		SessionID:         sessionID,
+		TokenID:           primitive.NewObjectID().Hex(),
+		TokenHash:         tokens.Hash(req.RefreshToken),
+		ParentTokenID:     parentTokenID,
+		DeviceFingerprint: req.DeviceFingerprint,
+		IssuedAt:          now,
+		LastUsedAt:        now,
+	})
+	user.Timestamps.LastTokenRefresh = now
+
+	if _, err := s.userDBservice.UpdateUser(req.InstanceId, user); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.auditWriter.Emit(models.AuditEntry{
+		InstanceID: req.InstanceId,
+		UserID:     req.UserId,
+		EventType:  audit.EventTokenRefreshed,
+		SourceIP:   sourceIPFromContext(ctx),
+		UserAgent:  userAgentFromContext(ctx),
+	})
+
+	return &api.ServiceStatus{
+		Status: api.ServiceStatus_NORMAL,
+		Msg:    "token refresh time updated",
+	}, nil
+}
+
+// ListSessions returns the caller's active login sessions.
+func (s *userManagementServer) ListSessions(ctx context.Context, req *api.ListSessionsMsg) (*api.ListSessionsResponse, error) {
+	if req == nil || utils.IsTokenEmpty(req.Token) {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+
+	user, err := s.userDBservice.GetUserByID(req.Token.InstanceId, req.Token.Id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "user not found")
+	}
+
+	seen := map[string]bool{}
+	sessions := []*api.SessionInfo{}
+	for _, session := range user.Sessions {
+		if session.RevokedAt != 0 || seen[session.SessionID] {
+			continue
+		}
+		seen[session.SessionID] = true
+		sessions = append(sessions, &api.SessionInfo{
+			SessionId:         session.SessionID,
+			DeviceFingerprint: session.DeviceFingerprint,
+			IssuedAt:          session.IssuedAt,
+			LastUsedAt:        session.LastUsedAt,
+		})
+	}
+
+	return &api.ListSessionsResponse{Sessions: sessions}, nil
+}
+
+// RevokeSession logs out a single device by revoking its session lineage.
+func (s *userManagementServer) RevokeSession(ctx context.Context, req *api.RevokeSessionMsg) (*api.ServiceStatus, error) {
+	if req == nil || utils.IsTokenEmpty(req.Token) || req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+
+	user, err := s.userDBservice.GetUserByID(req.Token.InstanceId, req.Token.Id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "user not found")
+	}
+
+	user.RevokeSessionsBySessionID(req.SessionId, time.Now().Unix())
+
+	if _, err := s.userDBservice.UpdateUser(req.Token.InstanceId, user); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.auditWriter.Emit(models.AuditEntry{
+		InstanceID: req.Token.InstanceId,
+		UserID:     req.Token.Id,
+		EventType:  audit.EventSessionRevoked,
+		SourceIP:   sourceIPFromContext(ctx),
+		UserAgent:  userAgentFromContext(ctx),
+	})
+
+	return &api.ServiceStatus{Status: api.ServiceStatus_NORMAL, Msg: "session revoked"}, nil
+}
+
+// RevokeAllSessions logs out every device.
+func (s *userManagementServer) RevokeAllSessions(ctx context.Context, req *api.RevokeAllSessionsMsg) (*api.ServiceStatus, error) {
+	if req == nil || utils.IsTokenEmpty(req.Token) {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+
+	user, err := s.userDBservice.GetUserByID(req.Token.InstanceId, req.Token.Id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "user not found")
+	}
+
+	user.RevokeAllSessions(time.Now().Unix())
+
+	if _, err := s.userDBservice.UpdateUser(req.Token.InstanceId, user); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.auditWriter.Emit(models.AuditEntry{
+		InstanceID: req.Token.InstanceId,
+		UserID:     req.Token.Id,
+		EventType:  audit.EventSessionRevoked,
+		SourceIP:   sourceIPFromContext(ctx),
+		UserAgent:  userAgentFromContext(ctx),
+	})
+
+	return &api.ServiceStatus{Status: api.ServiceStatus_NORMAL, Msg: "all sessions revoked"}, nil
+}
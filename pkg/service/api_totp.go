@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/influenzanet/user-management-service/pkg/api"
+	"github.com/influenzanet/user-management-service/pkg/audit"
+	"github.com/influenzanet/user-management-service/pkg/models"
+	"github.com/influenzanet/user-management-service/pkg/pwhash"
+	"github.com/influenzanet/user-management-service/pkg/tokens"
+	"github.com/influenzanet/user-management-service/pkg/totp"
+	utils "github.com/influenzanet/user-management-service/utils"
+)
+
+const totpIssuer = "influenzanet"
+const totpRecoveryCodeCount = 10
+
+// EnableTOTP provisions a new (unconfirmed) TOTP secret and recovery codes
+// for the caller. 2FA only takes effect for login once the secret is
+// confirmed via ConfirmTOTP. Re-provisioning an already-confirmed secret
+// requires a reauth token, the same as DisableTOTP, so that a stolen access
+// token alone cannot be used to take over the account's second factor.
+func (s *userManagementServer) EnableTOTP(ctx context.Context, req *api.EnableTOTPMsg) (*api.EnableTOTPResponse, error) {
+	if req == nil || utils.IsTokenEmpty(req.Token) {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+
+	user, err := s.userDBservice.GetUserByID(req.Token.InstanceId, req.Token.Id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "user not found")
+	}
+
+	if user.Account.TOTPConfirmedAt > 0 {
+		if err := s.requireSensitiveActionToken(req.ReauthToken, req.Token.Id, tokens.ScopeTOTPEnable); err != nil {
+			return nil, err
+		}
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	recoveryCodes, err := totp.GenerateRecoveryCodes(totpRecoveryCodeCount)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	recoveryCodeHashes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hash, err := pwhash.HashPassword(code)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		recoveryCodeHashes[i] = hash
+	}
+
+	user.Account.TOTPSecret = secret
+	user.Account.TOTPConfirmedAt = 0
+	user.Account.TOTPRecoveryCodeHashes = recoveryCodeHashes
+	user.Account.LastTOTPCounter = 0
+
+	if _, err := s.userDBservice.UpdateUser(req.Token.InstanceId, user); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.EnableTOTPResponse{
+		Secret:          secret,
+		ProvisioningUri: totp.ProvisioningURI(totpIssuer, user.Account.AccountID, secret),
+		RecoveryCodes:   recoveryCodes,
+	}, nil
+}
+
+// ConfirmTOTP activates the secret provisioned by EnableTOTP once the user
+// proves possession of it.
+func (s *userManagementServer) ConfirmTOTP(ctx context.Context, req *api.ConfirmTOTPMsg) (*api.ServiceStatus, error) {
+	if req == nil || utils.IsTokenEmpty(req.Token) || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+
+	user, err := s.userDBservice.GetUserByID(req.Token.InstanceId, req.Token.Id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "user not found")
+	}
+	if user.Account.TOTPSecret == "" {
+		return nil, status.Error(codes.FailedPrecondition, "TOTP has not been enabled")
+	}
+
+	valid, counter, err := totp.Validate(user.Account.TOTPSecret, req.Code, user.Account.LastTOTPCounter)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if !valid {
+		return nil, status.Error(codes.InvalidArgument, "invalid code")
+	}
+
+	user.Account.LastTOTPCounter = counter
+	user.Account.TOTPConfirmedAt = time.Now().Unix()
+	if _, err := s.userDBservice.UpdateUser(req.Token.InstanceId, user); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.auditWriter.Emit(models.AuditEntry{
+		InstanceID: req.Token.InstanceId,
+		UserID:     req.Token.Id,
+		EventType:  audit.EventTOTPEnrolled,
+		SourceIP:   sourceIPFromContext(ctx),
+		UserAgent:  userAgentFromContext(ctx),
+	})
+
+	return &api.ServiceStatus{Status: api.ServiceStatus_NORMAL, Msg: "TOTP enabled"}, nil
+}
+
+// DisableTOTP removes 2FA from the account.
+func (s *userManagementServer) DisableTOTP(ctx context.Context, req *api.DisableTOTPMsg) (*api.ServiceStatus, error) {
+	if req == nil || utils.IsTokenEmpty(req.Token) {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+	if err := s.requireSensitiveActionToken(req.ReauthToken, req.Token.Id, tokens.ScopeTOTPDisable); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userDBservice.GetUserByID(req.Token.InstanceId, req.Token.Id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "user not found")
+	}
+
+	user.Account.TOTPSecret = ""
+	user.Account.TOTPConfirmedAt = 0
+	user.Account.TOTPRecoveryCodeHashes = nil
+	user.Account.LastTOTPCounter = 0
+
+	if _, err := s.userDBservice.UpdateUser(req.Token.InstanceId, user); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.auditWriter.Emit(models.AuditEntry{
+		InstanceID: req.Token.InstanceId,
+		UserID:     req.Token.Id,
+		EventType:  audit.EventTOTPDisabled,
+		SourceIP:   sourceIPFromContext(ctx),
+		UserAgent:  userAgentFromContext(ctx),
+	})
+
+	return &api.ServiceStatus{Status: api.ServiceStatus_NORMAL, Msg: "TOTP disabled"}, nil
+}
+
+// VerifyTOTP checks a second-factor code for an already-authenticated
+// session, e.g. to step up before a sensitive operation. It shares the same
+// per-IP/per-account throttle as LoginWithEmail, since a valid code is a
+// 1-in-a-million guess that unlimited parallel requests would make trivial.
+func (s *userManagementServer) VerifyTOTP(ctx context.Context, req *api.VerifyTOTPMsg) (*api.ServiceStatus, error) {
+	if req == nil || utils.IsTokenEmpty(req.Token) || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+	sourceIP := sourceIPFromContext(ctx)
+	userAgent := userAgentFromContext(ctx)
+
+	if allowed, retryAfter, err := s.loginThrottle.Allow(sourceIP); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	} else if !allowed {
+		return nil, status.Errorf(codes.ResourceExhausted, "too many failed attempts, try again in %s", retryAfter.Round(time.Second))
+	}
+
+	user, err := s.userDBservice.GetUserByID(req.Token.InstanceId, req.Token.Id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "user not found")
+	}
+	if user.Account.LockedUntil > time.Now().Unix() {
+		return nil, status.Error(codes.ResourceExhausted, "account temporarily locked due to too many failed attempts")
+	}
+
+	valid, err := s.verifySecondFactor(&user, req.Code)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if !valid {
+		s.recordLoginFailure(req.Token.InstanceId, user.ID.Hex(), sourceIP, userAgent)
+		if err := s.registerFailedLogin(req.Token.InstanceId, user); err != nil {
+			log.Printf("VerifyTOTP: failed to persist failed attempt: %s", err.Error())
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid code")
+	}
+
+	if _, err := s.userDBservice.UpdateUser(req.Token.InstanceId, user); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.resetLoginThrottle(req.Token.InstanceId, user)
+
+	return &api.ServiceStatus{Status: api.ServiceStatus_NORMAL, Msg: "code verified"}, nil
+}
+
+// LoginWithSecondFactor exchanges a login challenge token issued by
+// LoginWithEmail, plus a valid second-factor code, for the full UserAuthInfo.
+// It applies the same per-IP/per-account throttle as LoginWithEmail, since a
+// password alone would otherwise let an attacker brute-force the much
+// smaller TOTP code space with unlimited parallel requests.
+func (s *userManagementServer) LoginWithSecondFactor(ctx context.Context, req *api.LoginWithSecondFactorMsg) (*api.UserAuthInfo, error) {
+	if req == nil || req.ChallengeToken == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing argument")
+	}
+	sourceIP := sourceIPFromContext(ctx)
+	userAgent := userAgentFromContext(ctx)
+
+	if allowed, retryAfter, err := s.loginThrottle.Allow(sourceIP); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	} else if !allowed {
+		return nil, status.Errorf(codes.ResourceExhausted, "too many failed attempts, try again in %s", retryAfter.Round(time.Second))
+	}
+
+	claims, err := s.loginChallengeTokens.Validate(req.ChallengeToken)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired challenge")
+	}
+
+	user, err := s.userDBservice.GetUserByID(claims.InstanceID, claims.UserID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "user not found")
+	}
+	if user.Account.LockedUntil > time.Now().Unix() {
+		return nil, status.Error(codes.ResourceExhausted, "account temporarily locked due to too many failed attempts")
+	}
+
+	valid, err := s.verifySecondFactor(&user, req.Code)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if !valid {
+		s.recordLoginFailure(claims.InstanceID, user.ID.Hex(), sourceIP, userAgent)
+		if err := s.registerFailedLogin(claims.InstanceID, user); err != nil {
+			log.Printf("LoginWithSecondFactor: failed to persist failed attempt: %s", err.Error())
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid code")
+	}
+
+	if _, err := s.userDBservice.UpdateUser(claims.InstanceID, user); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.resetLoginThrottle(claims.InstanceID, user)
+
+	if err := s.userDBservice.UpdateLoginTime(claims.InstanceID, user.ID.Hex()); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.auditWriter.Emit(models.AuditEntry{
+		InstanceID: claims.InstanceID,
+		UserID:     user.ID.Hex(),
+		EventType:  audit.EventLoginSuccess,
+		SourceIP:   sourceIP,
+		UserAgent:  userAgent,
+	})
+
+	return buildUserAuthInfo(claims.InstanceID, user), nil
+}
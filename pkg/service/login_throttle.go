@@ -0,0 +1,102 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/influenzanet/user-management-service/pkg/audit"
+	"github.com/influenzanet/user-management-service/pkg/models"
+)
+
+// loginBackoffDelay returns how long a caller must wait after its most
+// recent failed attempt before trying again, given the account's current
+// FailedLoginAttempts count. The first few failures are free; after that the
+// delay grows until the account is locked outright (see
+// userManagementServer.registerFailedLogin).
+func loginBackoffDelay(attempts int) time.Duration {
+	switch {
+	case attempts <= 3:
+		return 0
+	case attempts == 4:
+		return time.Second
+	case attempts == 5:
+		return 2 * time.Second
+	case attempts == 6:
+		return 5 * time.Second
+	default:
+		return 15 * time.Second
+	}
+}
+
+// recordLoginFailure logs a failed login attempt for audit purposes and
+// counts it against the source IP's throttle, independently of whether the
+// target account could be identified.
+func (s *userManagementServer) recordLoginFailure(instanceID, userID, sourceIP, userAgent string) {
+	s.auditWriter.Emit(models.AuditEntry{
+		InstanceID: instanceID,
+		UserID:     userID,
+		EventType:  audit.EventLoginFailure,
+		SourceIP:   sourceIP,
+		UserAgent:  userAgent,
+	})
+	if sourceIP == "" {
+		return
+	}
+	if err := s.loginThrottle.RecordFailure(sourceIP); err != nil {
+		log.Printf("recordLoginFailure: failed to record IP throttle failure: %s", err.Error())
+	}
+}
+
+// registerFailedLogin persists a failed password check against user's
+// account, locking it once it accumulates too many failures and notifying
+// the account holder when that happens. The counter is incremented with an
+// atomic $inc (see userdb.UserDBService.IncrementFailedLoginAttempts) rather
+// than a read-modify-write, so concurrent failed attempts against the same
+// account can't clobber each other's update and silently evade the lockout.
+func (s *userManagementServer) registerFailedLogin(instanceID string, user models.User) error {
+	now := time.Now().Unix()
+
+	updated, err := s.userDBservice.IncrementFailedLoginAttempts(instanceID, user.ID.Hex(), now)
+	if err != nil {
+		return err
+	}
+
+	justLocked := updated.Account.LockedUntil <= now && updated.Account.FailedLoginAttempts >= s.rateLimit.MaxFailedAttempts
+	if !justLocked {
+		return nil
+	}
+
+	until := now + int64(s.rateLimit.LockoutDuration.Seconds())
+	if err := s.userDBservice.LockAccount(instanceID, updated.ID.Hex(), now, until); err != nil {
+		return err
+	}
+
+	s.auditWriter.Emit(models.AuditEntry{
+		InstanceID: instanceID,
+		UserID:     updated.ID.Hex(),
+		EventType:  audit.EventAccountLocked,
+	})
+
+	if err := s.clients.MessagingService.SendInstantEmail(
+		[]string{updated.Account.AccountID},
+		"account-locked",
+		instanceID,
+		updated.Account.PreferredLanguage,
+		map[string]string{},
+		false,
+	); err != nil {
+		log.Printf("registerFailedLogin: failed to send lockout notification: %s", err.Error())
+	}
+	return nil
+}
+
+// resetLoginThrottle clears an account's failed-login bookkeeping after a
+// successful login.
+func (s *userManagementServer) resetLoginThrottle(instanceID string, user models.User) {
+	if user.Account.FailedLoginAttempts == 0 && user.Account.LockedUntil == 0 {
+		return
+	}
+	if err := s.userDBservice.ResetFailedLoginAttempts(instanceID, user.ID.Hex()); err != nil {
+		log.Printf("resetLoginThrottle: failed to reset throttle counters: %s", err.Error())
+	}
+}
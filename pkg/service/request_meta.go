@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// sourceIPFromContext extracts the caller's IP address (without the port)
+// from gRPC peer info, for inclusion in audit log entries and as the key of
+// the per-IP login throttle.
+func sourceIPFromContext(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr := p.Addr.String()
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			return host
+		}
+		return addr
+	}
+	return ""
+}
+
+// userAgentFromContext extracts the caller's user-agent header, for
+// inclusion in audit log entries.
+func userAgentFromContext(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			return ua[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"github.com/influenzanet/user-management-service/pkg/models"
+	"github.com/influenzanet/user-management-service/pkg/pwhash"
+	"github.com/influenzanet/user-management-service/pkg/totp"
+)
+
+// SecondFactor is implemented by every two-factor-authentication method the
+// service supports (TOTP today, backup recovery codes, and pluggable future
+// methods such as email OTP), so LoginWithSecondFactor and VerifyTOTP can
+// check a code without knowing in advance which factor it belongs to.
+type SecondFactor interface {
+	// Verify reports whether code is currently valid for user. Implementations
+	// that consume single-use codes mutate user in place; the caller persists
+	// the change on success.
+	Verify(user *models.User, code string) (bool, error)
+}
+
+// totpFactor verifies RFC 6238 TOTP codes against the account's confirmed secret.
+type totpFactor struct{}
+
+func (totpFactor) Verify(user *models.User, code string) (bool, error) {
+	if user.Account.TOTPConfirmedAt == 0 || user.Account.TOTPSecret == "" {
+		return false, nil
+	}
+	valid, counter, err := totp.Validate(user.Account.TOTPSecret, code, user.Account.LastTOTPCounter)
+	if err != nil || !valid {
+		return false, err
+	}
+	user.Account.LastTOTPCounter = counter
+	return true, nil
+}
+
+// recoveryCodeFactor verifies single-use backup codes issued alongside TOTP
+// enrollment, consuming the matching code on success.
+type recoveryCodeFactor struct{}
+
+func (recoveryCodeFactor) Verify(user *models.User, code string) (bool, error) {
+	for i, hash := range user.Account.TOTPRecoveryCodeHashes {
+		match, err := pwhash.ComparePasswordWithHash(hash, code)
+		if err != nil {
+			continue
+		}
+		if match {
+			user.Account.TOTPRecoveryCodeHashes = append(
+				user.Account.TOTPRecoveryCodeHashes[:i],
+				user.Account.TOTPRecoveryCodeHashes[i+1:]...,
+			)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// verifySecondFactor tries every enrolled second factor against code,
+// returning true as soon as one accepts it.
+func (s *userManagementServer) verifySecondFactor(user *models.User, code string) (bool, error) {
+	for _, factor := range s.secondFactors {
+		ok, err := factor.Verify(user, code)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"log"
+	"net"
+
+	"google.golang.org/grpc"
+
+	"github.com/influenzanet/user-management-service/pkg/api"
+	"github.com/influenzanet/user-management-service/pkg/audit"
+	"github.com/influenzanet/user-management-service/pkg/dbs/globaldb"
+	"github.com/influenzanet/user-management-service/pkg/dbs/userdb"
+	"github.com/influenzanet/user-management-service/pkg/models"
+	"github.com/influenzanet/user-management-service/pkg/throttle"
+	"github.com/influenzanet/user-management-service/pkg/tokens"
+)
+
+type userManagementServer struct {
+	clients         *models.APIClients
+	userDBservice   *userdb.UserDBService
+	globalDBService *globaldb.GlobalDBService
+	JWT             models.JWTConfig
+
+	verificationTokens *tokens.VerificationService
+	verificationConfig models.VerificationConfig
+
+	reauthTokens *tokens.ReauthService
+
+	loginChallengeTokens *tokens.ChallengeService
+	secondFactors        []SecondFactor
+
+	auditWriter *audit.Writer
+	auditStore  audit.Store
+
+	rateLimit     models.RateLimitConfig
+	loginThrottle *throttle.Limiter
+}
+
+// RunServer starts the gRPC server on the given port and blocks until it exits.
+func RunServer(
+	ctx context.Context,
+	port string,
+	clients *models.APIClients,
+	userDBService *userdb.UserDBService,
+	globalDBService *globaldb.GlobalDBService,
+	jwtConfig models.JWTConfig,
+	verificationConfig models.VerificationConfig,
+	reauthConfig models.ReauthConfig,
+	challengeConfig models.ChallengeConfig,
+	auditStore audit.Store,
+	rateLimitConfig models.RateLimitConfig,
+) {
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+
+	s := &userManagementServer{
+		clients:              clients,
+		userDBservice:        userDBService,
+		globalDBService:      globalDBService,
+		JWT:                  jwtConfig,
+		verificationTokens:   tokens.NewVerificationService(verificationConfig.SigningKey, verificationConfig.TokenExpiry),
+		verificationConfig:   verificationConfig,
+		reauthTokens:         tokens.NewReauthService(reauthConfig.SigningKey, reauthConfig.TokenExpiry),
+		loginChallengeTokens: tokens.NewChallengeService(challengeConfig.SigningKey, challengeConfig.TokenExpiry),
+		auditStore:           auditStore,
+		auditWriter:          audit.NewWriter(auditStore, 256),
+		rateLimit:            rateLimitConfig,
+		loginThrottle: throttle.NewLimiter(
+			throttle.NewMemoryBackend(),
+			rateLimitConfig.LockoutDuration,
+			rateLimitConfig.MaxFailedAttempts,
+			rateLimitConfig.LockoutDuration,
+		),
+	}
+	s.secondFactors = []SecondFactor{&totpFactor{}, &recoveryCodeFactor{}}
+	defer s.auditWriter.Close()
+
+	grpcServer := grpc.NewServer()
+	api.RegisterUserManagementApiServer(grpcServer, s)
+
+	log.Printf("user-management-service listening on port %s", port)
+	if err := grpcServer.Serve(lis); err != nil {
+		log.Fatalf("failed to serve: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryBackend is an in-process Backend. It does not share state across
+// instances of the service, so under horizontal scaling each instance
+// enforces its own limit; swap in a shared backend (e.g. Redis/Valkey) once
+// that matters.
+type MemoryBackend struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+type memoryEntry struct {
+	failures     []time.Time
+	blockedUntil time.Time
+}
+
+// NewMemoryBackend creates an empty in-memory Backend.
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{entries: map[string]*memoryEntry{}}
+}
+
+func (b *MemoryBackend) RecordFailure(key string, now time.Time, window time.Duration) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e, ok := b.entries[key]
+	if !ok {
+		e = &memoryEntry{}
+		b.entries[key] = e
+	}
+
+	cutoff := now.Add(-window)
+	kept := e.failures[:0]
+	for _, t := range e.failures {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	e.failures = append(kept, now)
+
+	return len(e.failures), nil
+}
+
+func (b *MemoryBackend) BlockedUntil(key string) (time.Time, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e, ok := b.entries[key]
+	if !ok {
+		return time.Time{}, nil
+	}
+	return e.blockedUntil, nil
+}
+
+func (b *MemoryBackend) Block(key string, until time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e, ok := b.entries[key]
+	if !ok {
+		e = &memoryEntry{}
+		b.entries[key] = e
+	}
+	e.blockedUntil = until
+	return nil
+}
+
+func (b *MemoryBackend) Reset(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.entries, key)
+	return nil
+}
@@ -0,0 +1,73 @@
+// Package throttle tracks failed login attempts per key (e.g. source IP) and
+// decides when a key should be temporarily blocked. It is independent of the
+// per-account lockout persisted on models.Account: this package guards
+// against a single source hammering many different accounts.
+package throttle
+
+import "time"
+
+// Backend stores the sliding-window failure counts and block states that
+// Limiter builds on. MemoryBackend is the only implementation today; the
+// interface exists so a shared backend (e.g. Redis/Valkey) can be swapped in
+// for multi-instance deployments without changing Limiter or its callers.
+type Backend interface {
+	// RecordFailure registers a failure for key at time now and returns how
+	// many failures key has accumulated within the trailing window.
+	RecordFailure(key string, now time.Time, window time.Duration) (int, error)
+	// BlockedUntil returns the time until which key is blocked, or the zero
+	// time if key is not currently blocked.
+	BlockedUntil(key string) (time.Time, error)
+	// Block blocks key until until.
+	Block(key string, until time.Time) error
+	// Reset clears key's recorded failures and any block.
+	Reset(key string) error
+}
+
+// Limiter decides whether a key (typically a source IP) should be allowed to
+// attempt a login, based on how many failures it has accumulated recently.
+type Limiter struct {
+	backend     Backend
+	window      time.Duration
+	maxFailures int
+	lockout     time.Duration
+}
+
+// NewLimiter builds a Limiter that blocks a key for lockout once it has
+// accumulated maxFailures failures within window.
+func NewLimiter(backend Backend, window time.Duration, maxFailures int, lockout time.Duration) *Limiter {
+	return &Limiter{
+		backend:     backend,
+		window:      window,
+		maxFailures: maxFailures,
+		lockout:     lockout,
+	}
+}
+
+// Allow reports whether key may attempt a login right now, and if not, how
+// long until it may try again.
+func (l *Limiter) Allow(key string) (bool, time.Duration, error) {
+	until, err := l.backend.BlockedUntil(key)
+	if err != nil {
+		return false, 0, err
+	}
+	if until.IsZero() {
+		return true, 0, nil
+	}
+	if remaining := time.Until(until); remaining > 0 {
+		return false, remaining, nil
+	}
+	return true, 0, nil
+}
+
+// RecordFailure registers a failed attempt for key, blocking it for lockout
+// once it has accumulated maxFailures failures within window.
+func (l *Limiter) RecordFailure(key string) error {
+	count, err := l.backend.RecordFailure(key, time.Now(), l.window)
+	if err != nil {
+		return err
+	}
+	if count >= l.maxFailures {
+		return l.backend.Block(key, time.Now().Add(l.lockout))
+	}
+	return nil
+}
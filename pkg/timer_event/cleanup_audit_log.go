@@ -0,0 +1,26 @@
+package timer_event
+
+import (
+	"log"
+	"time"
+)
+
+// CleanUpAuditLog removes audit entries older than AuditLogRetention. Audit
+// entries exist for incident review, not indefinite storage, so they are
+// pruned on the same periodic schedule as unverified accounts.
+func (s *UserManagementTimerService) CleanUpAuditLog() {
+	log.Println("Starting clean up job for audit log:")
+	instances, err := s.globalDBService.GetAllInstances()
+	if err != nil {
+		log.Printf("unexpected error: %s", err.Error())
+	}
+	cutoff := time.Now().Unix() - s.AuditLogRetention
+	for _, instance := range instances {
+		count, err := s.auditDBService.DeleteBefore(instance.InstanceID, cutoff)
+		if err != nil {
+			log.Printf("unexpected error: %s", err.Error())
+			continue
+		}
+		log.Printf("%s: removed %d audit log entries", instance.InstanceID, count)
+	}
+}
@@ -5,6 +5,10 @@ import (
 	"time"
 )
 
+// CleanUpUnverifiedUsers removes accounts that never completed email
+// verification via VerifyAccount. It is a fallback safety net, not the
+// primary confirmation path: most accounts are confirmed by the user
+// following the link from the verification email sent on signup.
 func (s *UserManagementTimerService) CleanUpUnverifiedUsers() {
 	log.Println("Starting clean up job for unverified users:")
 	instances, err := s.globalDBService.GetAllInstances()
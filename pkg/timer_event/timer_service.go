@@ -0,0 +1,41 @@
+package timer_event
+
+import (
+	"github.com/influenzanet/user-management-service/pkg/dbs/auditdb"
+	"github.com/influenzanet/user-management-service/pkg/dbs/globaldb"
+	"github.com/influenzanet/user-management-service/pkg/dbs/userdb"
+)
+
+// UserManagementTimerService runs the periodic background jobs of the
+// user-management-service (cleanup, audit retention, ...).
+type UserManagementTimerService struct {
+	globalDBService *globaldb.GlobalDBService
+	userDBService   *userdb.UserDBService
+	auditDBService  *auditdb.AuditDBService
+
+	// CleanUpTimeThreshold is the number of seconds an unverified account is
+	// allowed to exist before it is removed by CleanUpUnverifiedUsers.
+	CleanUpTimeThreshold int64
+
+	// AuditLogRetention is how long audit entries are kept before
+	// CleanUpAuditLog removes them.
+	AuditLogRetention int64
+}
+
+// NewUserManagementTimerService creates a timer service instance wired to the
+// given DB services.
+func NewUserManagementTimerService(
+	globalDBService *globaldb.GlobalDBService,
+	userDBService *userdb.UserDBService,
+	auditDBService *auditdb.AuditDBService,
+	cleanUpTimeThreshold int64,
+	auditLogRetention int64,
+) *UserManagementTimerService {
+	return &UserManagementTimerService{
+		globalDBService:      globalDBService,
+		userDBService:        userDBService,
+		auditDBService:       auditDBService,
+		CleanUpTimeThreshold: cleanUpTimeThreshold,
+		AuditLogRetention:    auditLogRetention,
+	}
+}
@@ -0,0 +1,79 @@
+package tokens
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ChallengeClaims is the payload embedded in a signed login challenge token.
+type ChallengeClaims struct {
+	InstanceID string `json:"instanceID"`
+	UserID     string `json:"userID"`
+	ExpiresAt  int64  `json:"expiresAt"`
+}
+
+// ChallengeService mints and validates the short-lived challenge tokens
+// returned by LoginWithEmail when a second authentication factor is required,
+// and redeemed by LoginWithSecondFactor.
+type ChallengeService struct {
+	signingKey []byte
+	expiry     time.Duration
+}
+
+// NewChallengeService creates a service that signs challenge tokens with
+// signingKey and issues them with the given expiry.
+func NewChallengeService(signingKey string, expiry time.Duration) *ChallengeService {
+	return &ChallengeService{signingKey: []byte(signingKey), expiry: expiry}
+}
+
+// Issue mints a new login challenge token for the given user.
+func (s *ChallengeService) Issue(instanceID string, userID string) (token string, err error) {
+	claims := ChallengeClaims{InstanceID: instanceID, UserID: userID, ExpiresAt: time.Now().Add(s.expiry).Unix()}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	return encodedPayload + "." + s.sign(encodedPayload), nil
+}
+
+// Validate verifies the signature and expiry of a challenge token and returns
+// its claims.
+func (s *ChallengeService) Validate(token string) (ChallengeClaims, error) {
+	var claims ChallengeClaims
+
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return claims, errors.New("malformed challenge token")
+	}
+
+	expectedSig := s.sign(parts[0])
+	if subtle.ConstantTimeCompare([]byte(expectedSig), []byte(parts[1])) != 1 {
+		return claims, errors.New("invalid challenge token signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return claims, err
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return claims, err
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return claims, errors.New("challenge token expired")
+	}
+	return claims, nil
+}
+
+func (s *ChallengeService) sign(data string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,99 @@
+package tokens
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Purpose identifies what a verification token may be used for.
+type Purpose string
+
+const (
+	PurposeEmailConfirmation Purpose = "email-confirmation"
+)
+
+// Claims is the payload embedded in a signed verification token.
+type Claims struct {
+	InstanceID string  `json:"instanceID"`
+	AccountID  string  `json:"accountID"`
+	Purpose    Purpose `json:"purpose"`
+	ExpiresAt  int64   `json:"expiresAt"`
+}
+
+// VerificationService mints and validates short-lived, signed, single-use
+// tokens used for account confirmation and similar out-of-band verification
+// flows. Single-use enforcement is the caller's responsibility: Generate
+// returns a hash that should be persisted on the user document, and the
+// caller must compare it against Hash(presentedToken) and clear it on use.
+type VerificationService struct {
+	signingKey []byte
+	expiry     time.Duration
+}
+
+// NewVerificationService creates a service that signs tokens with signingKey
+// and issues them with the given expiry.
+func NewVerificationService(signingKey string, expiry time.Duration) *VerificationService {
+	return &VerificationService{signingKey: []byte(signingKey), expiry: expiry}
+}
+
+// Generate mints a new signed token for the given account and purpose. It
+// returns the opaque token string, the hash to persist for single-use
+// enforcement, and the token's expiry time.
+func (s *VerificationService) Generate(instanceID string, accountID string, purpose Purpose) (token string, tokenHash string, expiresAt int64, err error) {
+	expiresAt = time.Now().Add(s.expiry).Unix()
+	claims := Claims{InstanceID: instanceID, AccountID: accountID, Purpose: purpose, ExpiresAt: expiresAt}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", "", 0, err
+	}
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	token = encodedPayload + "." + s.sign(encodedPayload)
+	return token, Hash(token), expiresAt, nil
+}
+
+// Validate verifies the signature and expiry of a token and returns its claims.
+func (s *VerificationService) Validate(token string) (Claims, error) {
+	var claims Claims
+
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return claims, errors.New("malformed verification token")
+	}
+
+	expectedSig := s.sign(parts[0])
+	if subtle.ConstantTimeCompare([]byte(expectedSig), []byte(parts[1])) != 1 {
+		return claims, errors.New("invalid verification token signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return claims, err
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return claims, err
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return claims, errors.New("verification token expired")
+	}
+	return claims, nil
+}
+
+func (s *VerificationService) sign(data string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Hash returns the digest that should be compared against (and persisted as)
+// the single-use marker for a token.
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,122 @@
+// Package totp implements the HOTP/TOTP one-time password algorithms
+// (RFC 4226, RFC 6238) used for app-based two-factor authentication.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	period   = 30 // seconds per code, as per RFC 6238 default
+	digits   = 6
+	skewSize = 1 // accept one period before/after to absorb clock drift
+)
+
+// GenerateRecoveryCodes returns n random, human-typable single-use backup
+// codes to be shown to the user once and stored hashed thereafter.
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	const alphabet = "23456789abcdefghjkmnpqrstuvwxyz" // avoid ambiguous characters
+	codes := make([]string, n)
+	for i := range codes {
+		raw := make([]byte, 10)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		code := make([]byte, len(raw))
+		for j, b := range raw {
+			code[j] = alphabet[int(b)%len(alphabet)]
+		}
+		codes[i] = fmt.Sprintf("%s-%s", code[:5], code[5:])
+	}
+	return codes, nil
+}
+
+// GenerateSecret returns a new random base32-encoded TOTP secret.
+func GenerateSecret() (string, error) {
+	raw := make([]byte, 20) // 160 bits, as recommended by RFC 4226
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw), nil
+}
+
+// ProvisioningURI builds the otpauth:// URI that authenticator apps consume
+// to enroll the secret, typically rendered to the user as a QR code.
+func ProvisioningURI(issuer string, accountName string, secret string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprintf("%d", digits))
+	v.Set("period", fmt.Sprintf("%d", period))
+
+	label := url.PathEscape(fmt.Sprintf("%s:%s", issuer, accountName))
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, v.Encode())
+}
+
+// GenerateCode computes the TOTP code for secret at the given time.
+func GenerateCode(secret string, at time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	counter := uint64(at.Unix() / period)
+	return hotp(key, counter), nil
+}
+
+// Validate reports whether code is a valid, not-yet-used TOTP code for
+// secret, allowing for a small amount of clock drift between client and
+// server. lastCounter is the counter value of the most recently accepted
+// code for this secret (0 if none yet); a code for a counter at or before
+// lastCounter is rejected even if it's otherwise correct, so a code observed
+// once (shoulder-surfed, logged, intercepted) can't be replayed for the rest
+// of its validity window. On success, Validate returns the counter the code
+// was accepted for; the caller must persist it as the new lastCounter.
+func Validate(secret string, code string, lastCounter uint64) (bool, uint64, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return false, lastCounter, err
+	}
+
+	now := time.Now().Unix() / period
+	for skew := -skewSize; skew <= skewSize; skew++ {
+		counter := uint64(now + int64(skew))
+		if counter <= lastCounter {
+			continue
+		}
+		expected := hotp(key, counter)
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return true, counter, nil
+		}
+	}
+	return false, lastCounter, nil
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	return base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+}
+
+func hotp(key []byte, counter uint64) string {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(buf)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	truncated := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	code := truncated % uint32(math.Pow10(digits))
+	return fmt.Sprintf("%0*d", digits, code)
+}
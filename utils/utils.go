@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"regexp"
+
+	"github.com/influenzanet/user-management-service/pkg/api"
+)
+
+var emailRegexp = regexp.MustCompile(`^[a-zA-Z0-9.!#$%&'*+\/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)+$`)
+
+// CheckEmailFormat reports whether the given string is a syntactically valid email address.
+func CheckEmailFormat(email string) bool {
+	return emailRegexp.MatchString(email)
+}
+
+// CheckPasswordFormat reports whether the given password satisfies the minimum
+// strength requirements (length only for now).
+func CheckPasswordFormat(password string) bool {
+	return len(password) >= 8
+}
+
+// IsTokenEmpty reports whether the given parsed token info is missing required fields.
+func IsTokenEmpty(token *api.TokenInfos) bool {
+	return token == nil || token.Id == "" || token.InstanceId == ""
+}